@@ -2,24 +2,24 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 
 	"github.com/google/go-github/v60/github"
-	"github.com/tmc/langchaingo/llms"
-	"github.com/tmc/langchaingo/llms/openai"
-	"github.com/tmc/langchaingo/prompts"
 	"golang.org/x/oauth2"
-)
-
-// LLMProvider represents the LLM provider type
-type LLMProvider string
 
-const (
-	ProviderVLLM    LLMProvider = "vllm"    // Direct vLLM
-	ProviderLiteLLM LLMProvider = "litellm" // LiteLLM proxy
+	"github.com/kaskol10/self-hosted-renovate-review/dependency"
+	"github.com/kaskol10/self-hosted-renovate-review/helm"
+	"github.com/kaskol10/self-hosted-renovate-review/llmbackend"
+	"github.com/kaskol10/self-hosted-renovate-review/releasenotes"
+	"github.com/kaskol10/self-hosted-renovate-review/reviewdiff"
+	"github.com/kaskol10/self-hosted-renovate-review/structuredanalysis"
 )
 
 // PRAnalyzer handles PR analysis
@@ -27,15 +27,24 @@ type PRAnalyzer struct {
 	client      *github.Client
 	repo        string
 	prNumber    int
-	llmBaseURL  string      // Base URL for LLM (vLLM or LiteLLM)
-	llmAPIKey   string      // API key for LLM
-	llmProvider LLMProvider // Provider type
-	llmModel    string      // Model name
-	llm         llms.Model  // LangChainGo LLM instance (required)
+	llmProvider string                  // Provider name, e.g. "vllm", "anthropic", "ollama"
+	llmModel    string                  // Model name
+	llm         llmbackend.Backend      // Pluggable LLM backend instance (required)
+	failOnRisk  structuredanalysis.Risk // If set, Run exits non-zero when any change reaches this risk level
 }
 
-// NewPRAnalyzer creates a new PR analyzer instance
-func NewPRAnalyzer(repo string, prNumber int, githubToken, llmBaseURL, llmAPIKey string, llmProvider LLMProvider) (*PRAnalyzer, error) {
+// SetFailOnRisk configures Run to fail (via ErrRiskThresholdExceeded) when
+// the structured analysis finds any dependency change at or above risk.
+// Leaving it unset (the zero value) disables the gate.
+func (a *PRAnalyzer) SetFailOnRisk(risk structuredanalysis.Risk) {
+	a.failOnRisk = risk
+}
+
+// NewPRAnalyzer creates a new PR analyzer instance. llmProvider is looked up
+// in the llmbackend registry, so any provider registered via
+// llmbackend.RegisterProvider (including ones this package never imports
+// directly) works here.
+func NewPRAnalyzer(repo string, prNumber int, githubToken, llmBaseURL, llmAPIKey, llmProvider string) (*PRAnalyzer, error) {
 	ctx := context.Background()
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: githubToken},
@@ -43,78 +52,27 @@ func NewPRAnalyzer(repo string, prNumber int, githubToken, llmBaseURL, llmAPIKey
 	tc := oauth2.NewClient(ctx, ts)
 	client := github.NewClient(tc)
 
-	// Default LLM URL if not provided
-	if llmBaseURL == "" {
-		switch llmProvider {
-		case ProviderLiteLLM:
-			llmBaseURL = "http://localhost:4000/v1" // LiteLLM default port
-		case ProviderVLLM:
-			llmBaseURL = "http://localhost:8000/v1" // vLLM default port
-		default:
-			llmBaseURL = "http://localhost:8000/v1"
-		}
-	}
-
-	// Normalize URL - remove /chat/completions if present (should be base URL)
-	llmBaseURL = strings.TrimSuffix(llmBaseURL, "/chat/completions")
-	llmBaseURL = strings.TrimSuffix(llmBaseURL, "/v1/chat/completions")
-	// Ensure it ends with /v1
-	if !strings.HasSuffix(llmBaseURL, "/v1") {
-		if strings.HasSuffix(llmBaseURL, "/") {
-			llmBaseURL = llmBaseURL + "v1"
-		} else {
-			llmBaseURL = llmBaseURL + "/v1"
-		}
-	}
-
-	// Get model name from env or use default
+	// Get model name from env; individual backends fall back to their own
+	// default when this is empty.
 	modelName := os.Getenv("LLM_MODEL")
-	if modelName == "" {
-		modelName = "qwen3" // Default model name
+
+	llm, err := llmbackend.New(llmProvider, llmbackend.Config{
+		BaseURL: llmBaseURL,
+		APIKey:  llmAPIKey,
+		Model:   modelName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize LLM backend: %w", err)
 	}
 
-	analyzer := &PRAnalyzer{
+	return &PRAnalyzer{
 		client:      client,
 		repo:        repo,
 		prNumber:    prNumber,
-		llmBaseURL:  llmBaseURL,
-		llmAPIKey:   llmAPIKey,
 		llmProvider: llmProvider,
 		llmModel:    modelName,
-	}
-
-	// Initialize LangChainGo LLM (required)
-
-	// LangChainGo requires an API key, but vLLM doesn't need one
-	// Use provided key, or a dummy key if none provided
-	apiKey := llmAPIKey
-	if apiKey == "" {
-		// Check environment variable
-		apiKey = os.Getenv("OPENAI_API_KEY")
-		if apiKey == "" {
-			// Use dummy key for vLLM (it doesn't validate the key)
-			apiKey = "not-needed"
-		}
-	}
-
-	// Set OPENAI_API_KEY env var for LangChainGo (it reads from env)
-	// This is a workaround since LangChainGo checks for the env var
-	if os.Getenv("OPENAI_API_KEY") == "" {
-		os.Setenv("OPENAI_API_KEY", apiKey)
-	}
-
-	llm, err := openai.New(
-		openai.WithBaseURL(llmBaseURL),
-		openai.WithModel(modelName),
-		openai.WithAPIType(openai.APITypeOpenAI),
-		openai.WithToken(apiKey), // Explicitly set the token
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize LangChainGo LLM: %w", err)
-	}
-	analyzer.llm = llm
-
-	return analyzer, nil
+		llm:         llm,
+	}, nil
 }
 
 // GetPRInfo fetches PR information
@@ -158,6 +116,7 @@ func isDependencyFile(fileName string) bool {
 		"package.json", "package-lock.json", "yarn.lock", "pnpm-lock.yaml",
 		"requirements.txt", "pipfile", "poetry.lock", "pyproject.toml",
 		"go.mod", "go.sum",
+		"gopkg.lock", "gopkg.toml",
 		"cargo.toml", "cargo.lock",
 		"pom.xml", "build.gradle", "gradle.properties",
 		"*.csproj", "*.sln", "packages.config",
@@ -166,6 +125,8 @@ func isDependencyFile(fileName string) bool {
 		"pubspec.yaml",
 		"mix.exs", "mix.lock",
 		"podfile", "podfile.lock",
+		"chart.yaml", "chart.lock",
+		"requirements.yaml", "requirements.lock",
 	}
 
 	for _, depFile := range dependencyFiles {
@@ -195,16 +156,19 @@ func isDependencyFile(fileName string) bool {
 	return false
 }
 
+// FileDiff is a single dependency-related file's patch, plus its added
+// lines parsed with their line numbers in the new file so a finding about
+// that file can be anchored to a specific review comment position.
+type FileDiff struct {
+	FileName   string
+	Diff       string
+	AddedLines []reviewdiff.AddedLine
+}
+
 // GetFileDiffs collects file diffs from PR files for LLM analysis
 // Only includes dependency-related files to avoid processing unrelated changes
-func (a *PRAnalyzer) GetFileDiffs(files []*github.CommitFile) []struct {
-	FileName string
-	Diff     string
-} {
-	var diffs []struct {
-		FileName string
-		Diff     string
-	}
+func (a *PRAnalyzer) GetFileDiffs(files []*github.CommitFile) []FileDiff {
+	var diffs []FileDiff
 
 	fmt.Printf("📄 Processing %d file(s)...\n", len(files))
 	dependencyFileCount := 0
@@ -225,12 +189,10 @@ func (a *PRAnalyzer) GetFileDiffs(files []*github.CommitFile) []struct {
 		dependencyFileCount++
 
 		fmt.Printf("  📝 Collecting diff for %s\n", fileName)
-		diffs = append(diffs, struct {
-			FileName string
-			Diff     string
-		}{
-			FileName: fileName,
-			Diff:     patch,
+		diffs = append(diffs, FileDiff{
+			FileName:   fileName,
+			Diff:       patch,
+			AddedLines: reviewdiff.ParseAddedLines(patch),
 		})
 	}
 
@@ -238,15 +200,102 @@ func (a *PRAnalyzer) GetFileDiffs(files []*github.CommitFile) []struct {
 	return diffs
 }
 
-// AnalyzeWithLangChainGo uses LangChainGo for enhanced analysis with diff-based approach
-func (a *PRAnalyzer) AnalyzeWithLangChainGo(pr *github.PullRequest, diffs []struct {
-	FileName string
-	Diff     string
-}) (string, error) {
-	if a.llm == nil {
-		return "", fmt.Errorf("LangChainGo LLM not initialized")
+// ExtractDependencyChanges runs the deterministic dependency.ParseDiff parser
+// over every collected file diff. The result is authoritative ground truth:
+// it is computed from the diff text itself, not inferred by the LLM, so it
+// is used to correct/ground the model rather than the other way around.
+func (a *PRAnalyzer) ExtractDependencyChanges(diffs []FileDiff) []dependency.DependencyChange {
+	var changes []dependency.DependencyChange
+	for _, fileDiff := range diffs {
+		changes = append(changes, dependency.ParseDiff(fileDiff.FileName, fileDiff.Diff)...)
+	}
+	return changes
+}
+
+// ExtractHelmChanges scans the collected diffs for Helm chart manifests
+// (Chart.yaml, the legacy requirements.yaml) and their lockfiles
+// (Chart.lock, requirements.lock), merging declared constraint changes with
+// resolved version changes into per-dependency helm.ChartDependencyChange
+// records.
+func (a *PRAnalyzer) ExtractHelmChanges(diffs []FileDiff) []helm.ChartDependencyChange {
+	constraintChanges := map[string][2]string{}
+	resolvedChanges := map[string][2]string{}
+
+	for _, fileDiff := range diffs {
+		base := strings.ToLower(fileDiff.FileName)
+		switch {
+		case strings.HasSuffix(base, "chart.yaml") || strings.HasSuffix(base, "requirements.yaml"):
+			for name, v := range helm.ParseChartYAMLDiff(fileDiff.Diff) {
+				constraintChanges[name] = v
+			}
+		case strings.HasSuffix(base, "chart.lock") || strings.HasSuffix(base, "requirements.lock"):
+			for name, v := range helm.ParseLockfileDiff(fileDiff.Diff) {
+				resolvedChanges[name] = v
+			}
+		}
+	}
+
+	if len(constraintChanges) == 0 && len(resolvedChanges) == 0 {
+		return nil
 	}
+	return helm.Analyze(constraintChanges, resolvedChanges)
+}
 
+// formatHelmChanges renders Helm chart dependency changes as a markdown
+// table for injection into the LLM prompt, calling out constraint widening
+// explicitly since it's easy to miss when the resolved/pinned version looks
+// unchanged.
+func formatHelmChanges(changes []helm.ChartDependencyChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n**Helm Chart Dependency Changes (authoritative ground truth):**\n\n")
+	b.WriteString("| Name | Constraint | Resolved Version | Bump Type | Constraint Widened |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	for _, c := range changes {
+		constraint := fmt.Sprintf("%s → %s", c.OldConstraint, c.NewConstraint)
+		resolved := fmt.Sprintf("%s → %s", c.OldResolved, c.NewResolved)
+		widened := "no"
+		if c.ConstraintWidened {
+			widened = "⚠️ yes — future upgrades may pull in versions the old constraint excluded"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", c.Name, constraint, resolved, c.BumpType, widened)
+	}
+	return b.String()
+}
+
+// formatDependencyChanges renders extracted dependency changes as a markdown
+// table for injection into the LLM prompt as ground truth.
+func formatDependencyChanges(changes []dependency.DependencyChange) string {
+	if len(changes) == 0 {
+		return "No dependency changes were deterministically extracted from the diffs."
+	}
+
+	var b strings.Builder
+	b.WriteString("| Name | Ecosystem | Old Version | New Version | Bump Type | File |\n")
+	b.WriteString("|---|---|---|---|---|---|\n")
+	for _, c := range changes {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			c.Name, c.Ecosystem, c.OldVersion, c.NewVersion, c.BumpType, c.File)
+	}
+	return b.String()
+}
+
+// analysisContext is the shared prompt context built once from the PR and
+// its diffs, so AnalyzeStructured's prompt and any future analysis entry
+// point both read from the same ground truth.
+type analysisContext struct {
+	prTitle                  string
+	prBody                   string
+	diffSummary              string
+	dependencyChanges        []dependency.DependencyChange
+	dependencyChangesSummary string
+	releaseNotesSummary      string
+}
+
+func (a *PRAnalyzer) buildAnalysisContext(pr *github.PullRequest, diffs []FileDiff) analysisContext {
 	prTitle := ""
 	if pr.Title != nil {
 		prTitle = *pr.Title
@@ -272,223 +321,304 @@ func (a *PRAnalyzer) AnalyzeWithLangChainGo(pr *github.PullRequest, diffs []stru
 		diffSummary.WriteString(fmt.Sprintf("\n**File: %s**\n```diff\n%s\n```\n", fileDiff.FileName, diffContent))
 	}
 
-	// Create prompt template using LangChainGo
-	promptTemplate := prompts.NewPromptTemplate(
-		`You are an expert software engineer specializing in dependency management and breaking change analysis. Your task is to provide clear, actionable insights that help developers make informed decisions about dependency updates.
+	// Deterministically extract dependency changes before calling the LLM so
+	// the model is grounded in parsed fact rather than guessing versions from
+	// raw diff text.
+	dependencyChanges := a.ExtractDependencyChanges(diffs)
+	dependencyChangesSummary := formatDependencyChanges(dependencyChanges) + formatHelmChanges(a.ExtractHelmChanges(diffs))
 
-## Context
+	// Fetch upstream release notes for each detected bump so the LLM
+	// summarizes authoritative changelog text instead of speculating about
+	// breaking changes from version numbers alone.
+	releaseNotesByChange := releasenotes.FetchAll(context.Background(), releasenotes.DefaultSources(a.client), dependencyChanges)
+	releaseNotesSummary := releasenotes.Format(releaseNotesByChange)
 
-**PR Title:** {{.pr_title}}
-
-**PR Description:** {{.pr_description}}
-
-**Code Changes (Diffs):**
-{{.diff_summary}}
-
-## Analysis Requirements
-
-Analyze the provided diffs and provide a comprehensive, structured analysis. Follow this exact format:
-
-### 📦 1. Dependency Changes Summary
-
-List ALL dependency changes found in the diffs. For each change, specify:
-- **Package/Image Name**: Exact name from the diff
-- **Version Change**: Old version → New version (e.g., "1.2.3 → 2.0.0")
-- **Update Type**: Major / Minor / Patch / Docker image tag
-- **File Location**: Which file(s) contain this change
-
-Supported formats:
-- Node.js: package.json, package-lock.json, yarn.lock, pnpm-lock.yaml
-- Python: requirements.txt, Pipfile, poetry.lock, pyproject.toml
-- Go: go.mod, go.sum
-- Rust: Cargo.toml, Cargo.lock
-- Java: pom.xml, build.gradle
-- .NET: *.csproj, *.sln, packages.config
-- Ruby: Gemfile, Gemfile.lock
-- PHP: composer.json, composer.lock
-- Docker/Kubernetes: Look for "image:" lines or "repository:" + "tag:" pairs in YAML files
-
-### ⚠️ 2. Breaking Changes Risk Assessment
-
-For EACH dependency change, assess breaking change risk:
-
-**Risk Level**: 🔴 HIGH / 🟡 MEDIUM / 🟢 LOW
-
-**Reasoning**:
-- Semantic versioning analysis (major bumps = HIGH risk)
-- Known breaking changes in changelogs/release notes
-- Deprecation warnings or removed features
-- API/interface changes detected
-
-**Specific Breaking Changes** (if any):
-- List concrete breaking changes (e.g., "API method X removed", "Configuration format changed")
-- Reference specific versions or changelog entries if known
-
-### 📊 3. Impact Analysis
+	return analysisContext{
+		prTitle:                  prTitle,
+		prBody:                   prBody,
+		diffSummary:              diffSummary.String(),
+		dependencyChanges:        dependencyChanges,
+		dependencyChangesSummary: dependencyChangesSummary,
+		releaseNotesSummary:      releaseNotesSummary,
+	}
+}
 
-Assess the potential impact on the codebase:
+// maxStructuredRetries is how many times AnalyzeStructured will re-prompt
+// the LLM with the previous attempt's parse/validation error appended
+// before giving up.
+const maxStructuredRetries = 2
+
+// AnalyzeStructured requests a structured, schema-validated analysis
+// instead of free-form markdown prose. The rendered PR comment is then
+// built deterministically from the validated struct (structuredanalysis.RenderMarkdown),
+// so it no longer depends on the model following a markdown template
+// exactly, and the struct itself is usable for machine gating (e.g. a
+// --fail-on-risk CI check).
+func (a *PRAnalyzer) AnalyzeStructured(ctx context.Context, pr *github.PullRequest, diffs []FileDiff) (structuredanalysis.Result, error) {
+	if a.llm == nil {
+		return structuredanalysis.Result{}, fmt.Errorf("LLM backend not initialized")
+	}
 
-**Affected Areas**:
-- List specific files, modules, or components that might be affected
-- Identify services or features that depend on these changes
-- Note any transitive dependencies that might be impacted
+	ac := a.buildAnalysisContext(pr, diffs)
 
-**Potential Issues**:
-- Runtime errors or exceptions that might occur
-- Build/compilation issues
-- Performance implications
-- Security considerations
+	basePrompt := fmt.Sprintf(`You are an expert software engineer specializing in dependency management and breaking change analysis.
 
-**Severity**: 🔴 Critical / 🟡 Moderate / 🟢 Low
+## Context
 
-### 🔄 4. Migration Requirements
+**PR Title:** %s
 
-Provide actionable migration steps if needed:
+**PR Description:** %s
 
-**Required Actions** (if breaking changes detected):
-1. [Specific step 1 with code examples if applicable]
-2. [Specific step 2]
-3. [Continue as needed]
+**Extracted Dependency Changes (authoritative ground truth, computed from the diffs):**
+%s
 
-**Code Changes Needed**:
-- List specific code locations that need updates
-- Provide code examples or patterns if helpful
-- Note any configuration file changes
+**Upstream Release Notes (authoritative, fetched from the package's registry/GitHub releases):**
+%s
 
-**Estimated Effort**: [X hours/days] or "No changes required"
+## Task
 
-### 🧪 5. Testing Recommendations
+Assess the risk of each dependency change listed above.
 
-Provide specific, actionable testing guidance:
+%s`, ac.prTitle, ac.prBody, ac.dependencyChangesSummary, ac.releaseNotesSummary, structuredanalysis.SchemaInstructions)
 
-**Critical Test Areas**:
-- [Specific feature/component to test]
-- [Specific functionality to verify]
-- [Specific integration to check]
+	prompt := basePrompt
+	var lastErr error
+	for attempt := 0; attempt <= maxStructuredRetries; attempt++ {
+		var (
+			completion string
+			err        error
+		)
+		if jsonLLM, ok := a.llm.(llmbackend.JSONCapable); ok {
+			completion, err = jsonLLM.GenerateJSON(ctx, prompt, llmbackend.GenerateOptions{Temperature: 0.1, MaxTokens: 3000})
+		} else {
+			completion, err = a.llm.Generate(ctx, prompt, llmbackend.GenerateOptions{Temperature: 0.1, MaxTokens: 3000})
+		}
+		if err != nil {
+			return structuredanalysis.Result{}, fmt.Errorf("LLM backend call failed: %w", err)
+		}
 
-**Test Types**:
-- **Unit Tests**: [Specific test files or functions to update/run]
-- **Integration Tests**: [Specific integration scenarios to verify]
-- **Manual Testing**: [Specific user flows or features to manually test]
+		result, parseErr := structuredanalysis.Parse(completion)
+		if parseErr == nil {
+			return result, nil
+		}
 
-**Regression Risks**:
-- List specific areas where regressions are most likely
-- Suggest test cases to add if missing
+		lastErr = parseErr
+		prompt = fmt.Sprintf("%s\n\nYour previous response failed to parse: %v\n\nYour previous response was:\n%s\n\nRespond again, fixing the issue above and strictly following the required JSON shape.", basePrompt, parseErr, completion)
+	}
 
-### 🎯 6. Confidence Level & Recommendation
+	return structuredanalysis.Result{}, fmt.Errorf("failed to get valid structured output after %d attempts: %w", maxStructuredRetries+1, lastErr)
+}
 
-**Confidence Level**: 
-- 🔴 **LOW**: Significant uncertainty, requires thorough review
-- 🟡 **MEDIUM**: Some uncertainty, review recommended
-- 🟢 **HIGH**: High confidence, likely safe
+// ReviewFinding is a single risk assessment anchored to a specific line of a
+// specific file, ready to become a GitHub review comment.
+type ReviewFinding struct {
+	File     string
+	Line     int
+	Severity string
+	Message  string
+}
 
-**Reasoning**: [Explain why you assigned this confidence level]
+var lineFindingRe = regexp.MustCompile(`(?i)^SEVERITY:\s*(low|medium|high)\s*\|\s*MESSAGE:\s*(.+)$`)
 
-**Recommendation**: 
-- ✅ **MERGE**: Safe to merge, no action needed
-- ⚠️ **REVIEW REQUIRED**: Requires human review before merging
-- ❌ **DO NOT MERGE**: Contains breaking changes that need migration first
+// ErrRiskThresholdExceeded is returned by Run when --fail-on-risk is set and
+// the structured analysis finds a change at or above that risk level. main
+// checks for it with errors.Is to pick a distinct exit code for CI.
+var ErrRiskThresholdExceeded = errors.New("a dependency change met or exceeded the configured risk threshold")
 
-**Next Steps** (if not MERGE):
-1. [Specific action item 1]
-2. [Specific action item 2]
-3. [Continue as needed]
+// AnalyzeLineFindings assesses each dependency change individually and
+// anchors the result to the diff line that introduced its new version, so
+// it can be posted as an inline review comment rather than folded into one
+// summary wall of text. Changes whose new version can't be found in the
+// diff's added lines (and so can't be anchored to a position) are skipped.
+func (a *PRAnalyzer) AnalyzeLineFindings(ctx context.Context, diffs []FileDiff, changes []dependency.DependencyChange) []ReviewFinding {
+	addedLinesByFile := make(map[string][]reviewdiff.AddedLine, len(diffs))
+	for _, d := range diffs {
+		addedLinesByFile[d.FileName] = d.AddedLines
+	}
 
-## Output Format Guidelines
+	var findings []ReviewFinding
+	for _, change := range changes {
+		line := reviewdiff.FindLine(addedLinesByFile[change.File], change.NewVersion)
+		if line == 0 {
+			continue
+		}
+		severity, message := a.assessChangeSeverity(ctx, change)
+		findings = append(findings, ReviewFinding{File: change.File, Line: line, Severity: severity, Message: message})
+	}
+	return findings
+}
 
-- Use clear markdown formatting with headers, lists, and code blocks
-- Be specific and concrete - avoid vague statements
-- Provide actionable guidance - tell developers exactly what to do
-- Use emojis for visual clarity (as shown in the format above)
-- If no issues found, clearly state "No breaking changes detected" and recommend merge
-- If issues found, prioritize them by severity and provide clear remediation steps
+// assessChangeSeverity prompts the LLM for a single dependency change at a
+// time, asking for a one-line "SEVERITY: ... | MESSAGE: ..." response that's
+// cheap to parse. If the LLM call fails or doesn't follow the format, it
+// falls back to a medium-severity finding rather than dropping the comment.
+func (a *PRAnalyzer) assessChangeSeverity(ctx context.Context, change dependency.DependencyChange) (severity, message string) {
+	prompt := fmt.Sprintf(`Assess the risk of bumping %s (%s) from %s to %s in %s (bump type: %s).
+Respond with EXACTLY one line in the form:
+SEVERITY: <low|medium|high> | MESSAGE: <one concise sentence explaining the risk>`,
+		change.Name, change.Ecosystem, change.OldVersion, change.NewVersion, change.File, change.BumpType)
+
+	completion, err := a.llm.Generate(ctx, prompt, llmbackend.GenerateOptions{Temperature: 0.2, MaxTokens: 200})
+	if err != nil {
+		return "medium", fmt.Sprintf("Could not assess risk automatically: %v", err)
+	}
 
-## Important Notes
+	if m := lineFindingRe.FindStringSubmatch(strings.TrimSpace(completion)); m != nil {
+		return strings.ToLower(m[1]), strings.TrimSpace(m[2])
+	}
+	return "medium", strings.TrimSpace(completion)
+}
 
-- Base your analysis ONLY on the diffs provided - do not make assumptions
-- For Docker images, check both formats:
-  - Direct: image: registry/image:tag
-  - Structured: repository: "image" with tag: "version"
-- When in doubt about breaking changes, err on the side of caution
-- Provide specific file paths, function names, or code locations when possible
-- If you cannot determine something from the diffs, state "Cannot determine from provided diffs" rather than guessing`,
-		[]string{"pr_title", "pr_description", "diff_summary"},
-	)
+// PostReview posts one position-anchored comment per finding via GitHub's
+// PullRequests.CreateReview API, so reviewers see risk flagged on the exact
+// line a version bump occurs rather than having to cross-reference a
+// markdown wall against the diff themselves. It does nothing when there are
+// no findings: the overall narrative lives in the deduplicated issue
+// comment posted by PostOrUpdateComment, and reviews can't be edited across
+// runs the way an issue comment can, so posting one with no comments here
+// would just add to the noise this is meant to reduce.
+func (a *PRAnalyzer) PostReview(findings []ReviewFinding) error {
+	if len(findings) == 0 {
+		return nil
+	}
 
-	// Format the prompt
-	prompt, err := promptTemplate.Format(map[string]interface{}{
-		"pr_title":       prTitle,
-		"pr_description": prBody,
-		"diff_summary":   diffSummary.String(),
-	})
-	if err != nil {
-		return "", fmt.Errorf("failed to format prompt: %w", err)
+	parts := strings.Split(a.repo, "/")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid repo format: %s (expected owner/repo)", a.repo)
 	}
 
-	// Build the full prompt with system message
-	fullPrompt := fmt.Sprintf(`You are an expert software engineer specializing in dependency management and breaking change analysis. Your responses must be clear, actionable, and structured according to the format provided.
+	var comments []*github.DraftReviewComment
+	for _, f := range findings {
+		body := fmt.Sprintf("**%s risk**: %s", strings.ToUpper(f.Severity), f.Message)
+		comments = append(comments, &github.DraftReviewComment{
+			Path: github.String(f.File),
+			Body: github.String(body),
+			Line: github.Int(f.Line),
+			Side: github.String("RIGHT"),
+		})
+	}
 
-%s`, prompt)
+	review := &github.PullRequestReviewRequest{
+		Event:    github.String("COMMENT"),
+		Comments: comments,
+	}
 
-	// Call LLM using LangChainGo
 	ctx := context.Background()
-	completion, err := a.llm.Call(ctx, fullPrompt, llms.WithTemperature(0.3), llms.WithMaxTokens(3000))
+	_, _, err := a.client.PullRequests.CreateReview(ctx, parts[0], parts[1], a.prNumber, review)
 	if err != nil {
-		return "", fmt.Errorf("LangChainGo LLM call failed: %w", err)
+		return fmt.Errorf("failed to post review: %w", err)
 	}
 
-	// Format the response
-	analysis := fmt.Sprintf(`## 🤖 Renovate AI Analysis (Self-Hosted Models)
-
-%s
-
----
-*This analysis was automatically generated by Renovate AI using self-hosted models (%s/%s) via LangChainGo.*`, completion, a.llmProvider, a.llmModel)
-
-	return analysis, nil
+	return nil
 }
 
-// AnalyzeWithAI sends analysis request using LangChainGo (required)
-func (a *PRAnalyzer) AnalyzeWithAI(pr *github.PullRequest, diffs []struct {
-	FileName string
-	Diff     string
-}) (string, error) {
-	return a.AnalyzeWithLangChainGo(pr, diffs)
+// commentMarker is embedded in the analysis issue comment so
+// PostOrUpdateComment can find its own previous comment on a later run
+// instead of posting a new one every time. The version suffix lets a future
+// format change be distinguished from the current one instead of matching
+// it by accident. The hash is appended after a space, e.g.
+// "<!-- renovate-ai-review-id: v1 0123456789ab -->".
+const commentMarker = "<!-- renovate-ai-review-id: v1"
+
+var commentMarkerRe = regexp.MustCompile(`<!-- renovate-ai-review-id: v1 ([0-9a-f]+) -->`)
+
+// contentHash hashes the analyzed diffs deterministically, so
+// PostOrUpdateComment can tell whether anything material changed since the
+// last review and skip posting if not. GetFileDiffs returns diffs in a
+// stable order (the order GitHub returned the PR's files), so hashing their
+// concatenation is enough - no need to sort.
+func contentHash(diffs []FileDiff) string {
+	h := sha256.New()
+	for _, d := range diffs {
+		h.Write([]byte(d.FileName))
+		h.Write([]byte{0})
+		h.Write([]byte(d.Diff))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
 }
 
-// PostComment posts analysis as a comment on the PR
-func (a *PRAnalyzer) PostComment(analysis string) error {
+// PostOrUpdateComment posts body as a top-level PR comment, or updates the
+// bot's own previous comment in place if one already exists, rather than
+// adding a new comment on every run. Renovate PRs get force-pushed often
+// (rebases, the bot adding new commits), and without this the analysis
+// comment thread grows by one every time. hash is the current
+// contentHash(diffs); if it matches the hash embedded in the existing
+// comment's marker, nothing is posted since the analyzed diffs haven't
+// materially changed. It returns whether a comment was created or updated.
+func (a *PRAnalyzer) PostOrUpdateComment(body, hash string) (bool, error) {
 	parts := strings.Split(a.repo, "/")
 	if len(parts) != 2 {
-		return fmt.Errorf("invalid repo format: %s (expected owner/repo)", a.repo)
+		return false, fmt.Errorf("invalid repo format: %s (expected owner/repo)", a.repo)
 	}
+	owner, repoName := parts[0], parts[1]
+	ctx := context.Background()
 
-	// If analysis already includes the header (from LangChain), use it as-is
-	// Otherwise, add our header
-	var commentBody string
-	providerName := string(a.llmProvider)
-	if strings.Contains(analysis, "## 🤖 Renovate AI Analysis") {
-		commentBody = analysis
-	} else {
-		commentBody = fmt.Sprintf(`## 🤖 Renovate AI Analysis (Self-Hosted Models)
-
-%s
+	existing, existingHash, err := a.findExistingComment(ctx, owner, repoName)
+	if err != nil {
+		return false, err
+	}
 
----
-*This analysis was automatically generated by Renovate AI using self-hosted models (%s) via LangChainGo.*`, analysis, providerName)
+	if existing != nil && existingHash == hash {
+		fmt.Println("ℹ️  Analysis unchanged since last review; skipping comment update.")
+		return false, nil
 	}
 
-	comment := &github.IssueComment{
-		Body: &commentBody,
+	fullBody := fmt.Sprintf("%s\n\n%s %s -->\n", body, commentMarker, hash)
+
+	if existing != nil {
+		_, _, err := a.client.Issues.EditComment(ctx, owner, repoName, existing.GetID(), &github.IssueComment{
+			Body: github.String(fullBody),
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to update existing analysis comment: %w", err)
+		}
+		return true, nil
 	}
 
-	ctx := context.Background()
-	_, _, err := a.client.Issues.CreateComment(ctx, parts[0], parts[1], a.prNumber, comment)
+	_, _, err = a.client.Issues.CreateComment(ctx, owner, repoName, a.prNumber, &github.IssueComment{
+		Body: github.String(fullBody),
+	})
 	if err != nil {
-		return fmt.Errorf("failed to post comment: %w", err)
+		return false, fmt.Errorf("failed to post analysis comment: %w", err)
 	}
+	return true, nil
+}
 
-	return nil
+// findExistingComment scans the PR's issue comments for one carrying our
+// marker and returns it along with the hash embedded in it, or a nil comment
+// if none is found yet. It pages through every comment rather than trusting
+// the first page: on a PR with more than the API's default page size (30) -
+// not unusual once CI bots and human review threads pile on - our own
+// earlier comment can easily sit past page 1, and missing it would make
+// PostOrUpdateComment post a duplicate instead of updating it.
+func (a *PRAnalyzer) findExistingComment(ctx context.Context, owner, repoName string) (*github.IssueComment, string, error) {
+	opts := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		comments, resp, err := a.client.Issues.ListComments(ctx, owner, repoName, a.prNumber, opts)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list existing PR comments: %w", err)
+		}
+
+		for _, c := range comments {
+			if !strings.Contains(c.GetBody(), commentMarker) {
+				continue
+			}
+			m := commentMarkerRe.FindStringSubmatch(c.GetBody())
+			if m == nil {
+				return c, "", nil
+			}
+			return c, m[1], nil
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return nil, "", nil
 }
 
 // Run executes the full analysis workflow
@@ -517,20 +647,59 @@ func (a *PRAnalyzer) Run() error {
 
 	fmt.Printf("📦 Found %d file(s) with changes\n", len(diffs))
 
-	// Analyze with AI using LangChainGo (pass diffs directly)
-	providerName := string(a.llmProvider)
-	fmt.Printf("🤖 Running AI analysis with LangChainGo (%s)...\n", providerName)
-	analysis, err := a.AnalyzeWithAI(pr, diffs)
+	// Run the structured, schema-validated analysis. Its result both drives
+	// the posted comment (via structuredanalysis.RenderMarkdown, built
+	// deterministically in Go) and the optional --fail-on-risk gate below, so
+	// the comment a human reads and the risk the CI gate acts on can never
+	// disagree the way a separately-prompted free-form summary could.
+	ctx := context.Background()
+	fmt.Printf("🤖 Running AI analysis (%s)...\n", a.llmProvider)
+	result, err := a.AnalyzeStructured(ctx, pr, diffs)
 	if err != nil {
 		return fmt.Errorf("AI analysis failed: %w", err)
 	}
+	analysis := structuredanalysis.RenderMarkdown(result)
 
-	// Post comment
-	if err := a.PostComment(analysis); err != nil {
+	// Assess each dependency change individually so risky ones can be
+	// flagged inline at the exact line their version bump occurs.
+	dependencyChanges := a.ExtractDependencyChanges(diffs)
+	findings := a.AnalyzeLineFindings(ctx, diffs, dependencyChanges)
+
+	// Post the narrative summary as a single comment, updating our own
+	// previous one in place (keyed by a hash of the analyzed diffs) instead
+	// of piling up a new comment every re-run.
+	hash := contentHash(diffs)
+	posted, err := a.PostOrUpdateComment(analysis, hash)
+	if err != nil {
 		return err
 	}
+	if posted {
+		fmt.Printf("✅ Posted analysis comment to PR #%d\n", a.prNumber)
+	}
+
+	// Post one inline, position-anchored comment per finding - but only when
+	// the analysis itself was new. Reviews can't be updated in place across
+	// runs the way the summary comment can, so posting unconditionally here
+	// would spam a fresh set of inline comments on every re-run (CI re-runs,
+	// "re-run failed jobs") even when the diffs haven't materially changed.
+	if posted {
+		if err := a.PostReview(findings); err != nil {
+			return err
+		}
+	}
+	if posted && len(findings) > 0 {
+		fmt.Printf("✅ Posted review with %d inline comment(s) to PR #%d\n", len(findings), a.prNumber)
+	}
+
+	// When a risk gate is configured, fail the build if any change meets or
+	// exceeds it.
+	if a.failOnRisk != "" {
+		if highest := result.HighestRisk(); highest.AtLeast(a.failOnRisk) {
+			fmt.Printf("🚨 Highest risk found (%s) meets or exceeds --fail-on-risk=%s\n", highest, a.failOnRisk)
+			return fmt.Errorf("%w: highest risk %q >= threshold %q", ErrRiskThresholdExceeded, highest, a.failOnRisk)
+		}
+	}
 
-	fmt.Printf("✅ Posted analysis comment to PR #%d\n", a.prNumber)
 	fmt.Println("✅ Analysis complete!")
 
 	return nil
@@ -541,9 +710,10 @@ func main() {
 		repo        = flag.String("repo", "", "Repository name (owner/repo)")
 		prNumber    = flag.Int("pr-number", 0, "PR number")
 		githubToken = flag.String("github-token", "", "GitHub token")
-		llmURL      = flag.String("llm-url", "", "LLM API base URL (default: http://localhost:8000/v1 for vLLM, http://localhost:4000/v1 for LiteLLM)")
-		llmKey      = flag.String("llm-key", "", "LLM API key (optional)")
-		llmProvider = flag.String("llm-provider", "vllm", "LLM provider: 'vllm' (direct) or 'litellm' (proxy)")
+		llmURL      = flag.String("llm-url", "", "LLM API base URL (provider-specific default if omitted)")
+		llmKey      = flag.String("llm-key", "", "LLM API key / token (optional, provider-dependent)")
+		llmProvider = flag.String("llm-provider", "vllm", fmt.Sprintf("LLM provider (one of: %s)", strings.Join(llmbackend.Providers(), ", ")))
+		failOnRisk  = flag.String("fail-on-risk", "", "Fail (non-zero exit) if any dependency change reaches this risk level (low|medium|high); empty disables the gate")
 	)
 	flag.Parse()
 
@@ -554,20 +724,18 @@ func main() {
 	}
 
 	// Get LLM provider
-	providerStr := *llmProvider
-	if providerStr == "" {
-		providerStr = os.Getenv("LLM_PROVIDER")
-		if providerStr == "" {
-			providerStr = "vllm" // Default to vLLM
+	provider := *llmProvider
+	if provider == "" {
+		provider = os.Getenv("LLM_PROVIDER")
+		if provider == "" {
+			provider = "vllm" // Default to vLLM
 		}
 	}
-	provider := LLMProvider(strings.ToLower(providerStr))
-	if provider != ProviderVLLM && provider != ProviderLiteLLM {
-		fmt.Fprintf(os.Stderr, "Error: Invalid LLM provider '%s'. Must be 'vllm' or 'litellm'\n", providerStr)
-		os.Exit(1)
-	}
+	provider = strings.ToLower(provider)
 
-	// Get LLM URL from env if not provided
+	// Get LLM URL from env if not provided. Provider-specific defaults (e.g.
+	// vLLM's :8000, LiteLLM's :4000) are applied by the backend factory
+	// itself when this is left empty.
 	llmBaseURL := *llmURL
 	if llmBaseURL == "" {
 		// Try new env var first, then fallback to old vLLM-specific var for backward compatibility
@@ -575,14 +743,6 @@ func main() {
 		if llmBaseURL == "" {
 			llmBaseURL = os.Getenv("VLLM_API_URL") // Backward compatibility
 		}
-		if llmBaseURL == "" {
-			// Use provider-specific defaults
-			if provider == ProviderLiteLLM {
-				llmBaseURL = "http://localhost:4000/v1"
-			} else {
-				llmBaseURL = "http://localhost:8000/v1"
-			}
-		}
 	}
 
 	// Get LLM key from env if not provided
@@ -595,14 +755,22 @@ func main() {
 		}
 	}
 
-	// LangChainGo is now mandatory - always initialized
 	analyzer, err := NewPRAnalyzer(*repo, *prNumber, *githubToken, llmBaseURL, llmAPIKey, provider)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "❌ Error initializing analyzer: %v\n", err)
 		os.Exit(1)
 	}
 
+	if *failOnRisk != "" {
+		risk := structuredanalysis.Risk(strings.ToLower(*failOnRisk))
+		analyzer.SetFailOnRisk(risk)
+	}
+
 	if err := analyzer.Run(); err != nil {
+		if errors.Is(err, ErrRiskThresholdExceeded) {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(2)
+		}
 		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
 		os.Exit(1)
 	}
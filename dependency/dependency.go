@@ -0,0 +1,153 @@
+// Package dependency deterministically extracts dependency version changes
+// from unified diffs of common package-manager manifests and lockfiles,
+// before any of that information is handed to an LLM. The LLM is good at
+// summarizing *why* a bump might be risky, but it regularly hallucinates
+// *what* changed when it only sees raw diff text, so every version pair fed
+// into the prompt should come from here rather than from model inference.
+package dependency
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Ecosystem identifies the package manager / registry a dependency belongs to.
+type Ecosystem string
+
+const (
+	EcosystemNPM     Ecosystem = "npm"
+	EcosystemGo      Ecosystem = "go"
+	EcosystemPython  Ecosystem = "python"
+	EcosystemRust    Ecosystem = "cargo"
+	EcosystemJava    Ecosystem = "maven"
+	EcosystemPHP     Ecosystem = "composer"
+	EcosystemRuby    Ecosystem = "bundler"
+	EcosystemDocker  Ecosystem = "docker"
+	EcosystemUnknown Ecosystem = "unknown"
+)
+
+// BumpType classifies the semantic weight of a version transition.
+type BumpType string
+
+const (
+	BumpMajor   BumpType = "major"
+	BumpMinor   BumpType = "minor"
+	BumpPatch   BumpType = "patch"
+	BumpUnknown BumpType = "unknown"
+)
+
+// DependencyChange is a single dependency version transition detected in a
+// PR diff. It is the authoritative ground truth passed into the LLM prompt;
+// the model should describe and assess these, not re-derive them.
+type DependencyChange struct {
+	Name       string
+	Ecosystem  Ecosystem
+	OldVersion string
+	NewVersion string
+	File       string
+	BumpType   BumpType
+}
+
+// semver is a minimally-parsed "major.minor.patch" version. Pre-release and
+// build metadata are stripped before parsing; fields that aren't present or
+// aren't numeric are left at -1 so ComputeBumpType can tell a real 0 apart
+// from "couldn't parse this".
+type semver struct {
+	major, minor, patch int
+}
+
+func parseSemver(v string) (semver, bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	// Strip pre-release/build metadata (e.g. "1.2.3-beta.1+build5").
+	if i := strings.IndexAny(v, "-+"); i != -1 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	sv := semver{major: -1, minor: -1, patch: -1}
+	nums := make([]int, 0, 3)
+	for _, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			break
+		}
+		nums = append(nums, n)
+	}
+	if len(nums) == 0 {
+		return sv, false
+	}
+	sv.major = nums[0]
+	if len(nums) > 1 {
+		sv.minor = nums[1]
+	}
+	if len(nums) > 2 {
+		sv.patch = nums[2]
+	}
+	return sv, true
+}
+
+// CompareVersions compares two version strings as semver, returning -1, 0,
+// or 1 per the usual Compare convention. ok is false if either version
+// doesn't parse as semver, in which case the returned order is meaningless.
+func CompareVersions(a, b string) (order int, ok bool) {
+	svA, okA := parseSemver(a)
+	svB, okB := parseSemver(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	if svA.major != svB.major {
+		return cmp(svA.major, svB.major), true
+	}
+	if svA.minor != svB.minor {
+		return cmp(svA.minor, svB.minor), true
+	}
+	return cmp(svA.patch, svB.patch), true
+}
+
+func cmp(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ComputeBumpType classifies the transition from oldVersion to newVersion
+// using semver rules. Versions that don't parse as semver (git SHAs, "latest",
+// range expressions left unresolved, etc.) yield BumpUnknown rather than a
+// guess.
+//
+// Per semver's own "anything may change at any time" rule for 0.x releases,
+// a minor bump on a pre-1.0 package (0.x -> 0.y, y != x) is treated as
+// BumpMajor: maintainers routinely ship breaking changes in 0.x minor
+// releases, and flagging it as a plain "minor" would understate the risk.
+func ComputeBumpType(oldVersion, newVersion string) BumpType {
+	oldSV, oldOK := parseSemver(oldVersion)
+	newSV, newOK := parseSemver(newVersion)
+	if !oldOK || !newOK {
+		return BumpUnknown
+	}
+
+	if oldSV.major != newSV.major {
+		return BumpMajor
+	}
+	if oldSV.major == 0 {
+		// Pre-1.0: any minor change is potentially breaking.
+		if oldSV.minor != newSV.minor {
+			return BumpMajor
+		}
+		if oldSV.patch != newSV.patch {
+			return BumpMinor
+		}
+		return BumpUnknown
+	}
+	if oldSV.minor != newSV.minor {
+		return BumpMinor
+	}
+	if oldSV.patch != newSV.patch {
+		return BumpPatch
+	}
+	return BumpUnknown
+}
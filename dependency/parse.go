@@ -0,0 +1,361 @@
+package dependency
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ParseDiff extracts DependencyChange records from a unified diff patch for
+// a single file. fileName is the path as reported by the PR (e.g. from
+// github.CommitFile.Filename); the parser selected is based on its base name
+// or extension. Files that aren't recognized manifests/lockfiles yield nil.
+func ParseDiff(fileName, patch string) []DependencyChange {
+	base := strings.ToLower(filepath.Base(fileName))
+
+	switch {
+	case base == "package.json":
+		return parseKeyValueJSON(patch, fileName, EcosystemNPM)
+	case base == "go.mod":
+		return parseGoMod(patch, fileName)
+	case base == "requirements.txt":
+		return parseRequirementsTxt(patch, fileName)
+	case base == "cargo.toml":
+		return parseCargoToml(patch, fileName)
+	case base == "pom.xml":
+		return parsePomXML(patch, fileName)
+	case base == "composer.json":
+		return parseKeyValueJSON(patch, fileName, EcosystemPHP)
+	case base == "gemfile":
+		return parseGemfile(patch, fileName)
+	case base == "gopkg.lock" || base == "gopkg.toml":
+		return parseGopkg(patch, fileName)
+	case strings.HasSuffix(base, ".yaml") || strings.HasSuffix(base, ".yml"):
+		return parseYAMLImageTag(patch, fileName)
+	default:
+		return nil
+	}
+}
+
+// diffLinePairs walks a unified diff and pairs each removed ("-") line with
+// the nearest subsequent added ("+") line for the same manifest entry, under
+// the assumption that dependency bumps appear as a removed line followed by
+// an added line naming the same dependency. Pending removals are tracked per
+// name (not as a single shared slot) so a hunk with several consecutive
+// removals followed by several consecutive additions - the normal shape of a
+// grouped Renovate bump - pairs each name with its own old value instead of
+// only the last one. Context lines (and lines that don't match namePattern)
+// are ignored.
+func diffLinePairs(patch string, namePattern *regexp.Regexp) map[string][2]string {
+	pairs := map[string][2]string{}
+	pending := map[string]string{}
+
+	for _, line := range strings.Split(patch, "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		switch line[0] {
+		case '-':
+			if strings.HasPrefix(line, "---") {
+				continue
+			}
+			m := namePattern.FindStringSubmatch(line[1:])
+			if m == nil {
+				continue
+			}
+			pending[m[1]] = m[2]
+		case '+':
+			if strings.HasPrefix(line, "+++") {
+				continue
+			}
+			m := namePattern.FindStringSubmatch(line[1:])
+			if m == nil {
+				continue
+			}
+			name, newVal := m[1], m[2]
+			if old, ok := pending[name]; ok {
+				pairs[name] = [2]string{old, newVal}
+				delete(pending, name)
+			}
+		}
+	}
+	return pairs
+}
+
+// sortedNames returns pairs' keys in sorted order, so callers that build a
+// []DependencyChange by ranging over a diffLinePairs result get a
+// deterministic order instead of Go's randomized map iteration order.
+func sortedNames(pairs map[string][2]string) []string {
+	names := make([]string, 0, len(pairs))
+	for name := range pairs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var jsonKeyValueRe = regexp.MustCompile(`"([^"]+)"\s*:\s*"\^?~?([0-9][^"]*)"`)
+
+// jsonDepSectionOpenRe matches the opening line of a dependency-bearing
+// object in package.json ("dependencies"/"devDependencies"/etc.) or
+// composer.json ("require"/"require-dev"), so parseKeyValueJSON only treats
+// matches inside one of those objects as dependency entries rather than any
+// digit-looking string value in the file (e.g. the package's own top-level
+// "version" field).
+var jsonDepSectionOpenRe = regexp.MustCompile(`"(dependencies|devDependencies|peerDependencies|optionalDependencies|require|require-dev)"\s*:\s*\{`)
+
+// parseKeyValueJSON handles package.json/composer.json, where a dependency
+// bump is a "name": "version" line inside a dependencies-style object. It
+// tracks brace depth relative to the most recently opened such object so a
+// line is only treated as a dependency entry while still inside one.
+func parseKeyValueJSON(patch, fileName string, eco Ecosystem) []DependencyChange {
+	pairs := map[string][2]string{}
+	pending := map[string]string{}
+	depth := 0 // 0 means not currently inside a tracked dependency object
+
+	for _, raw := range strings.Split(patch, "\n") {
+		if len(raw) == 0 {
+			continue
+		}
+
+		marker := raw[0]
+		var line string
+		switch marker {
+		case '+', '-':
+			if strings.HasPrefix(raw, "+++") || strings.HasPrefix(raw, "---") {
+				continue
+			}
+			line = raw[1:]
+		case '@':
+			continue
+		case ' ':
+			line = raw[1:]
+		default:
+			line = raw
+		}
+
+		if depth == 0 {
+			if jsonDepSectionOpenRe.MatchString(line) {
+				depth = 1
+			}
+			continue
+		}
+
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if depth <= 0 {
+			depth = 0
+			continue
+		}
+
+		if marker != '+' && marker != '-' {
+			continue
+		}
+		m := jsonKeyValueRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name, val := m[1], m[2]
+		switch marker {
+		case '-':
+			pending[name] = val
+		case '+':
+			if old, ok := pending[name]; ok {
+				pairs[name] = [2]string{old, val}
+				delete(pending, name)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(pairs))
+	for name := range pairs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	changes := make([]DependencyChange, 0, len(names))
+	for _, name := range names {
+		old := pairs[name]
+		changes = append(changes, newChange(name, eco, old[0], old[1], fileName))
+	}
+	return changes
+}
+
+var goModRequireRe = regexp.MustCompile(`^\s*([^\s]+)\s+(v[0-9][^\s]*)`)
+
+func parseGoMod(patch, fileName string) []DependencyChange {
+	pairs := diffLinePairs(patch, goModRequireRe)
+	changes := make([]DependencyChange, 0, len(pairs))
+	for _, name := range sortedNames(pairs) {
+		old := pairs[name]
+		changes = append(changes, newChange(name, EcosystemGo, old[0], old[1], fileName))
+	}
+	return changes
+}
+
+var requirementsTxtRe = regexp.MustCompile(`^\s*([A-Za-z0-9_.\-]+)\s*==\s*([0-9][^\s#]*)`)
+
+func parseRequirementsTxt(patch, fileName string) []DependencyChange {
+	pairs := diffLinePairs(patch, requirementsTxtRe)
+	changes := make([]DependencyChange, 0, len(pairs))
+	for _, name := range sortedNames(pairs) {
+		old := pairs[name]
+		changes = append(changes, newChange(name, EcosystemPython, old[0], old[1], fileName))
+	}
+	return changes
+}
+
+var cargoTomlRe = regexp.MustCompile(`^([A-Za-z0-9_.\-]+)\s*=\s*"\^?~?([0-9][^"]*)"`)
+
+func parseCargoToml(patch, fileName string) []DependencyChange {
+	pairs := diffLinePairs(patch, cargoTomlRe)
+	changes := make([]DependencyChange, 0, len(pairs))
+	for _, name := range sortedNames(pairs) {
+		old := pairs[name]
+		changes = append(changes, newChange(name, EcosystemRust, old[0], old[1], fileName))
+	}
+	return changes
+}
+
+var pomVersionRe = regexp.MustCompile(`<version>([0-9][^<]*)</version>`)
+var pomArtifactRe = regexp.MustCompile(`<artifactId>([^<]+)</artifactId>`)
+
+// parsePomXML handles the common Maven layout where <artifactId> and
+// <version> are on separate lines; it uses the nearest preceding
+// <artifactId> seen on either side of the diff as the dependency name for a
+// changed <version> line.
+func parsePomXML(patch, fileName string) []DependencyChange {
+	var changes []DependencyChange
+	var pendingName string
+	var pendingOld string
+	havePending := false
+
+	for _, line := range strings.Split(patch, "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		content := line[1:]
+		if m := pomArtifactRe.FindStringSubmatch(content); m != nil && (line[0] == ' ' || line[0] == '-' || line[0] == '+') {
+			pendingName = m[1]
+		}
+		switch line[0] {
+		case '-':
+			if m := pomVersionRe.FindStringSubmatch(content); m != nil {
+				pendingOld = m[1]
+				havePending = true
+			}
+		case '+':
+			if m := pomVersionRe.FindStringSubmatch(content); m != nil && havePending {
+				changes = append(changes, newChange(pendingName, EcosystemJava, pendingOld, m[1], fileName))
+				havePending = false
+			}
+		}
+	}
+	return changes
+}
+
+var gemfileRe = regexp.MustCompile(`^\s*gem\s+["']([^"']+)["'],\s*["']~?>?\s*([0-9][^"']*)["']`)
+
+func parseGemfile(patch, fileName string) []DependencyChange {
+	pairs := diffLinePairs(patch, gemfileRe)
+	changes := make([]DependencyChange, 0, len(pairs))
+	for _, name := range sortedNames(pairs) {
+		old := pairs[name]
+		changes = append(changes, newChange(name, EcosystemRuby, old[0], old[1], fileName))
+	}
+	return changes
+}
+
+var gopkgRe = regexp.MustCompile(`(?:name|revision|version)\s*=\s*"([^"]*)".*?\bversion\s*=\s*"([0-9][^"]*)"`)
+
+// parseGopkg handles Gopkg.lock/Gopkg.toml [[projects]] blocks where "name"
+// and "version" appear as separate attribute lines rather than on one line.
+func parseGopkg(patch, fileName string) []DependencyChange {
+	var changes []DependencyChange
+	nameRe := regexp.MustCompile(`^\s*name\s*=\s*"([^"]+)"`)
+	versionRe := regexp.MustCompile(`^\s*version\s*=\s*"([0-9][^"]*)"`)
+
+	var currentName string
+	var pendingOld string
+	havePending := false
+
+	for _, line := range strings.Split(patch, "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		content := line[1:]
+		if m := nameRe.FindStringSubmatch(content); m != nil && line[0] != '-' {
+			currentName = m[1]
+		}
+		switch line[0] {
+		case '-':
+			if m := versionRe.FindStringSubmatch(content); m != nil {
+				pendingOld = m[1]
+				havePending = true
+			}
+		case '+':
+			if m := versionRe.FindStringSubmatch(content); m != nil && havePending {
+				changes = append(changes, newChange(currentName, EcosystemGo, pendingOld, m[1], fileName))
+				havePending = false
+			}
+		}
+	}
+	return changes
+}
+
+var yamlImageRe = regexp.MustCompile(`^\s*image:\s*["']?([^"'\s:]+):([^"'\s]+)["']?`)
+var yamlRepositoryRe = regexp.MustCompile(`^\s*repository:\s*["']?([^"'\s]+)["']?`)
+var yamlTagRe = regexp.MustCompile(`^\s*tag:\s*["']?([^"'\s]+)["']?`)
+
+// parseYAMLImageTag handles both the inline "image: repo:tag" form and the
+// split "repository: repo" / "tag: tag" form used by most Helm values files.
+func parseYAMLImageTag(patch, fileName string) []DependencyChange {
+	var changes []DependencyChange
+
+	// Inline "image:" pairs are a simple old/new pairing on the repo:tag string.
+	imagePairs := diffLinePairs(patch, yamlImageRe)
+	for _, name := range sortedNames(imagePairs) {
+		old := imagePairs[name]
+		changes = append(changes, newChange(name, EcosystemDocker, old[0], old[1], fileName))
+	}
+
+	// Split repository/tag: pair the nearest preceding "repository:" with a
+	// changed "tag:" line.
+	var currentRepo string
+	var pendingOld string
+	havePending := false
+
+	for _, line := range strings.Split(patch, "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		content := line[1:]
+		if m := yamlRepositoryRe.FindStringSubmatch(content); m != nil && line[0] != '-' {
+			currentRepo = m[1]
+		}
+		switch line[0] {
+		case '-':
+			if m := yamlTagRe.FindStringSubmatch(content); m != nil {
+				pendingOld = m[1]
+				havePending = true
+			}
+		case '+':
+			if m := yamlTagRe.FindStringSubmatch(content); m != nil && havePending {
+				changes = append(changes, newChange(currentRepo, EcosystemDocker, pendingOld, m[1], fileName))
+				havePending = false
+			}
+		}
+	}
+	return changes
+}
+
+func newChange(name string, eco Ecosystem, old, new, fileName string) DependencyChange {
+	return DependencyChange{
+		Name:       name,
+		Ecosystem:  eco,
+		OldVersion: old,
+		NewVersion: new,
+		File:       fileName,
+		BumpType:   ComputeBumpType(old, new),
+	}
+}
@@ -0,0 +1,131 @@
+package dependency
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		patch    string
+		want     []DependencyChange
+	}{
+		{
+			name:     "package.json sorts changes and ignores top-level version field",
+			fileName: "package.json",
+			patch: `@@ -1,8 +1,8 @@
+ {
+-  "version": "1.0.0",
++  "version": "1.0.1",
+   "dependencies": {
+-    "lodash": "4.17.20",
++    "lodash": "4.17.21",
+-    "express": "4.17.0",
++    "express": "4.17.1"
+   }
+ }`,
+			want: []DependencyChange{
+				newChange("express", EcosystemNPM, "4.17.0", "4.17.1", "package.json"),
+				newChange("lodash", EcosystemNPM, "4.17.20", "4.17.21", "package.json"),
+			},
+		},
+		{
+			name:     "composer.json scopes matches to require block",
+			fileName: "composer.json",
+			patch: `@@ -1,6 +1,6 @@
+ {
+   "require": {
+-    "monolog/monolog": "1.0.0",
++    "monolog/monolog": "1.1.0"
+   }
+ }`,
+			want: []DependencyChange{
+				newChange("monolog/monolog", EcosystemPHP, "1.0.0", "1.1.0", "composer.json"),
+			},
+		},
+		{
+			name:     "go.mod pairs multiple bumps in sorted order",
+			fileName: "go.mod",
+			patch: `@@ -2,6 +2,6 @@
+ require (
+-	github.com/zeta/zeta v1.0.0
++	github.com/zeta/zeta v1.1.0
+-	github.com/alpha/alpha v2.0.0
++	github.com/alpha/alpha v2.1.0
+ )`,
+			want: []DependencyChange{
+				newChange("github.com/alpha/alpha", EcosystemGo, "v2.0.0", "v2.1.0", "go.mod"),
+				newChange("github.com/zeta/zeta", EcosystemGo, "v1.0.0", "v1.1.0", "go.mod"),
+			},
+		},
+		{
+			name:     "requirements.txt",
+			fileName: "requirements.txt",
+			patch: `@@ -1,2 +1,2 @@
+-requests==2.25.0
++requests==2.25.1`,
+			want: []DependencyChange{
+				newChange("requests", EcosystemPython, "2.25.0", "2.25.1", "requirements.txt"),
+			},
+		},
+		{
+			name:     "Cargo.toml",
+			fileName: "Cargo.toml",
+			patch: `@@ -1,2 +1,2 @@
+-serde = "1.0.100"
++serde = "1.0.101"`,
+			want: []DependencyChange{
+				newChange("serde", EcosystemRust, "1.0.100", "1.0.101", "Cargo.toml"),
+			},
+		},
+		{
+			name:     "Gemfile",
+			fileName: "Gemfile",
+			patch: `@@ -1,2 +1,2 @@
+-gem "rails", "6.1.0"
++gem "rails", "6.1.1"`,
+			want: []DependencyChange{
+				newChange("rails", EcosystemRuby, "6.1.0", "6.1.1", "Gemfile"),
+			},
+		},
+		{
+			name:     "Gopkg.lock pairs name with version across separate lines",
+			fileName: "Gopkg.lock",
+			patch: `@@ -1,6 +1,6 @@
+ [[projects]]
+   name = "github.com/pkg/errors"
+-  version = "0.8.0"
++  version = "0.9.0"`,
+			want: []DependencyChange{
+				newChange("github.com/pkg/errors", EcosystemGo, "0.8.0", "0.9.0", "Gopkg.lock"),
+			},
+		},
+		{
+			name:     "values.yaml inline image tag",
+			fileName: "values.yaml",
+			patch: `@@ -1,2 +1,2 @@
+-image: nginx:1.20.0
++image: nginx:1.21.0`,
+			want: []DependencyChange{
+				newChange("nginx", EcosystemDocker, "1.20.0", "1.21.0", "values.yaml"),
+			},
+		},
+		{
+			name:     "unrecognized file yields nil",
+			fileName: "README.md",
+			patch:    "@@ -1,1 +1,1 @@\n-foo\n+bar",
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseDiff(tt.fileName, tt.patch)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDiff(%q) = %#v, want %#v", tt.fileName, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Package helm analyzes Helm chart dependency changes. Unlike a plain
+// package.json/go.mod bump, a Helm dependency entry is a semver *constraint*
+// (e.g. "~1.2.3", "^1.0.0", ">=1.0.0 <2.0.0") that a lockfile (Chart.lock or
+// the legacy requirements.lock) resolves to a concrete version. The pinned
+// version can look unchanged in the lockfile while the constraint itself
+// widens in Chart.yaml/requirements.yaml, which is a real upgrade-behavior
+// risk that a diff-only view of the lockfile misses entirely.
+package helm
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kaskol10/self-hosted-renovate-review/dependency"
+)
+
+// ChartDependencyChange describes a single Helm chart dependency as it
+// changed across a PR: the declared constraint in Chart.yaml/requirements.yaml
+// and, where available, the resolved version from Chart.lock/requirements.lock.
+type ChartDependencyChange struct {
+	Name string
+
+	// OldConstraint/NewConstraint are the raw version constraints declared in
+	// Chart.yaml (apiVersion v2) or the legacy requirements.yaml.
+	OldConstraint string
+	NewConstraint string
+
+	// OldResolved/NewResolved are the concrete versions pinned in the
+	// corresponding lockfile, if one was part of the diff.
+	OldResolved string
+	NewResolved string
+
+	BumpType dependency.BumpType
+
+	// ConstraintWidened is true when NewConstraint permits a broader range of
+	// future versions than OldConstraint, even if OldResolved == NewResolved.
+	ConstraintWidened bool
+}
+
+// chartYAMLNameRe and chartYAMLVersionRe match a "- name: foo" / "  version: ~1.2.3" pair as they
+// appear, one per line, under a v2 Chart.yaml `dependencies:` block or a
+// legacy requirements.yaml `dependencies:` block (both use the same shape).
+var chartYAMLNameRe = regexp.MustCompile(`^\s*-?\s*name:\s*["']?([^"'\s]+)["']?`)
+var chartYAMLVersionRe = regexp.MustCompile(`^\s*version:\s*["']?([^"'\s]+)["']?`)
+
+// lockVersionRe matches a resolved "version: 1.2.3" line under a Chart.lock
+// or requirements.lock `dependencies:` block.
+var lockVersionRe = chartYAMLVersionRe
+
+// ParseChartYAMLDiff extracts declared constraint changes from a unified
+// diff of Chart.yaml or requirements.yaml. It pairs each dependency's
+// preceding "name:" line with its "version:" line, the same way the chart
+// itself associates them.
+func ParseChartYAMLDiff(patch string) map[string][2]string {
+	return pairNameVersion(patch, chartYAMLNameRe, chartYAMLVersionRe)
+}
+
+// ParseLockfileDiff extracts resolved version changes from a unified diff of
+// Chart.lock or requirements.lock.
+func ParseLockfileDiff(patch string) map[string][2]string {
+	return pairNameVersion(patch, chartYAMLNameRe, lockVersionRe)
+}
+
+// pairNameVersion walks a unified diff tracking the most recently seen
+// dependency "name:" line, and records old/new version pairs for "version:"
+// lines that change under that name.
+func pairNameVersion(patch string, nameRe, versionRe *regexp.Regexp) map[string][2]string {
+	changes := map[string][2]string{}
+
+	var currentName string
+	var pendingOld string
+	havePending := false
+
+	for _, line := range strings.Split(patch, "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		content := line[1:]
+		if m := nameRe.FindStringSubmatch(content); m != nil && line[0] != '-' {
+			currentName = m[1]
+			havePending = false
+		}
+		switch line[0] {
+		case '-':
+			if m := versionRe.FindStringSubmatch(content); m != nil {
+				pendingOld = m[1]
+				havePending = true
+			}
+		case '+':
+			if m := versionRe.FindStringSubmatch(content); m != nil && havePending && currentName != "" {
+				changes[currentName] = [2]string{pendingOld, m[1]}
+				havePending = false
+			}
+		}
+	}
+	return changes
+}
+
+// Analyze combines constraint changes (from Chart.yaml/requirements.yaml)
+// with resolved version changes (from Chart.lock/requirements.lock) into
+// per-dependency ChartDependencyChange records. Either map may be empty if
+// only one of the two files was part of the diff.
+func Analyze(constraintChanges, resolvedChanges map[string][2]string) []ChartDependencyChange {
+	names := map[string]struct{}{}
+	for name := range constraintChanges {
+		names[name] = struct{}{}
+	}
+	for name := range resolvedChanges {
+		names[name] = struct{}{}
+	}
+
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	var out []ChartDependencyChange
+	for _, name := range sortedNames {
+		c := ChartDependencyChange{Name: name}
+
+		if cv, ok := constraintChanges[name]; ok {
+			c.OldConstraint, c.NewConstraint = cv[0], cv[1]
+			c.ConstraintWidened = constraintWidened(cv[0], cv[1])
+		}
+		if rv, ok := resolvedChanges[name]; ok {
+			c.OldResolved, c.NewResolved = rv[0], rv[1]
+			c.BumpType = dependency.ComputeBumpType(rv[0], rv[1])
+		}
+
+		out = append(out, c)
+	}
+	return out
+}
+
+// constraintPermissiveness ranks how broad a range of future resolved
+// versions a constraint admits, from 0 (exact pin) to 4 (unconstrained).
+// This is intentionally coarse: it only needs to detect *widening*
+// (constraintWidened), not resolve the constraint itself.
+func constraintPermissiveness(constraint string) int {
+	c := strings.TrimSpace(constraint)
+	switch {
+	case c == "" || c == "*":
+		return 4
+	case strings.Contains(c, ">=") || strings.Contains(c, ">") || strings.Contains(c, "<"):
+		return 3
+	case strings.HasPrefix(c, "^"):
+		return 2
+	case strings.HasPrefix(c, "~"):
+		return 1
+	default:
+		return 0 // exact pin, e.g. "1.2.3"
+	}
+}
+
+// constraintWidened reports whether newConstraint permits strictly more
+// future versions than oldConstraint.
+func constraintWidened(oldConstraint, newConstraint string) bool {
+	return constraintPermissiveness(newConstraint) > constraintPermissiveness(oldConstraint)
+}
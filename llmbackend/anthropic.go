@@ -0,0 +1,115 @@
+package llmbackend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+func init() {
+	RegisterProvider("anthropic", newAnthropicBackend)
+}
+
+const anthropicAPIURL = "https://api.anthropic.com/v1/messages"
+const anthropicAPIVersion = "2023-06-01"
+
+// anthropicBackend talks to the Anthropic Messages API directly, bypassing
+// LangChainGo (whose OpenAI-shaped abstractions don't map cleanly onto it).
+type anthropicBackend struct {
+	apiKey string
+	model  string
+}
+
+func (b *anthropicBackend) Name() string { return "anthropic" }
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float64            `json:"temperature"`
+	Messages    []anthropicMessage `json:"messages"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Text string `json:"text"`
+	} `json:"content"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (b *anthropicBackend) Generate(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	maxTokens := opts.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = 3000
+	}
+
+	reqBody := anthropicRequest{
+		Model:       b.model,
+		MaxTokens:   maxTokens,
+		Temperature: opts.Temperature,
+		Messages:    []anthropicMessage{{Role: "user", Content: prompt}},
+	}
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Anthropic request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, anthropicAPIURL, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to build Anthropic request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", b.apiKey)
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("anthropic request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Anthropic response: %w", err)
+	}
+
+	var result anthropicResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to parse Anthropic response: %w", err)
+	}
+	if result.Error != nil {
+		return "", fmt.Errorf("anthropic error: %s", result.Error.Message)
+	}
+	if len(result.Content) == 0 {
+		return "", fmt.Errorf("anthropic response contained no content blocks")
+	}
+
+	return result.Content[0].Text, nil
+}
+
+func newAnthropicBackend(cfg Config) (Backend, error) {
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("ANTHROPIC_API_KEY")
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("anthropic provider requires an API key (--llm-key or ANTHROPIC_API_KEY)")
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = "claude-3-5-sonnet-20241022"
+	}
+
+	return &anthropicBackend{apiKey: apiKey, model: model}, nil
+}
@@ -0,0 +1,103 @@
+package llmbackend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+)
+
+func init() {
+	RegisterProvider("bedrock", newBedrockBackend)
+}
+
+// bedrockBackend invokes an AWS Bedrock model via the bedrockruntime
+// InvokeModel API. Credentials are resolved through the default AWS SDK
+// credential chain (env vars, shared config, instance/task role), the same
+// as every other AWS-backed tool in this environment - there's no
+// Bedrock-specific API key to configure.
+type bedrockBackend struct {
+	client  *bedrockruntime.Client
+	modelID string
+}
+
+func (b *bedrockBackend) Name() string { return "bedrock" }
+
+// anthropicBedrockRequest is the request body for Anthropic Claude models
+// served through Bedrock, which use Anthropic's native message schema
+// rather than a Bedrock-specific one.
+type anthropicBedrockRequest struct {
+	AnthropicVersion string             `json:"anthropic_version"`
+	MaxTokens        int                `json:"max_tokens"`
+	Temperature      float64            `json:"temperature"`
+	Messages         []anthropicMessage `json:"messages"`
+}
+
+type anthropicBedrockResponse struct {
+	Content []struct {
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+func (b *bedrockBackend) Generate(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	maxTokens := opts.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = 3000
+	}
+
+	reqBody := anthropicBedrockRequest{
+		AnthropicVersion: "bedrock-2023-05-31",
+		MaxTokens:        maxTokens,
+		Temperature:      opts.Temperature,
+		Messages:         []anthropicMessage{{Role: "user", Content: prompt}},
+	}
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Bedrock request: %w", err)
+	}
+
+	out, err := b.client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
+		ModelId:     aws.String(b.modelID),
+		Body:        payload,
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("bedrock InvokeModel failed: %w", err)
+	}
+
+	var result anthropicBedrockResponse
+	if err := json.Unmarshal(out.Body, &result); err != nil {
+		return "", fmt.Errorf("failed to parse Bedrock response: %w", err)
+	}
+	if len(result.Content) == 0 {
+		return "", fmt.Errorf("bedrock response contained no content blocks")
+	}
+
+	return result.Content[0].Text, nil
+}
+
+func newBedrockBackend(cfg Config) (Backend, error) {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		return nil, fmt.Errorf("bedrock provider requires AWS_REGION to be set")
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(), awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config for Bedrock: %w", err)
+	}
+
+	modelID := cfg.Model
+	if modelID == "" {
+		modelID = "anthropic.claude-3-5-sonnet-20241022-v2:0"
+	}
+
+	return &bedrockBackend{
+		client:  bedrockruntime.NewFromConfig(awsCfg),
+		modelID: modelID,
+	}, nil
+}
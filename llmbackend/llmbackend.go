@@ -0,0 +1,86 @@
+// Package llmbackend abstracts "send a prompt, get completion text back"
+// behind a single Backend interface so the analyzer isn't hard-wired to
+// OpenAI-compatible endpoints. Providers register themselves into an open
+// registry (RegisterProvider) instead of the analyzer hard-coding a closed
+// set of provider names, so adding a new backend never requires touching
+// cmd/analyzer.
+package llmbackend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// GenerateOptions carries the handful of generation parameters every
+// provider in practice supports. Provider-specific knobs belong in that
+// provider's Config/env handling, not here.
+type GenerateOptions struct {
+	Temperature float64
+	MaxTokens   int
+}
+
+// Backend is a single LLM completion endpoint. Implementations hide
+// whatever transport (LangChainGo, a provider SDK, or a raw HTTP call) they
+// use to get there.
+type Backend interface {
+	// Name identifies the backend for logging/attribution, e.g. "anthropic"
+	// or "ollama".
+	Name() string
+	Generate(ctx context.Context, prompt string, opts GenerateOptions) (string, error)
+}
+
+// JSONCapable is an optional capability a Backend may additionally
+// implement when its underlying API can be told to constrain its output to
+// valid JSON (e.g. OpenAI's response_format). Callers that want structured
+// output should type-assert for this and fall back to Generate plus
+// prompt-level schema instructions when it isn't implemented.
+type JSONCapable interface {
+	GenerateJSON(ctx context.Context, prompt string, opts GenerateOptions) (string, error)
+}
+
+// Config carries the provider-agnostic settings every backend accepts. A
+// Factory is free to additionally read provider-specific configuration from
+// the environment (e.g. AWS_REGION for Bedrock, OLLAMA_HOST for Ollama)
+// since threading every provider's knobs through this struct would defeat
+// the point of keeping providers pluggable.
+type Config struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+}
+
+// Factory constructs a Backend from Config. Factories should apply their
+// own sensible defaults (default base URL, default model) when the
+// corresponding Config field is empty.
+type Factory func(cfg Config) (Backend, error)
+
+var registry = map[string]Factory{}
+
+// RegisterProvider adds a backend factory under name (matched
+// case-insensitively by New). Called from each backend's init().
+func RegisterProvider(name string, factory Factory) {
+	registry[strings.ToLower(name)] = factory
+}
+
+// Providers returns the currently registered provider names, for
+// usage/help text.
+func Providers() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	return names
+}
+
+// New builds the Backend registered under provider. It returns an error
+// naming the known providers if provider isn't registered, rather than
+// silently falling back to a default - a typo in --llm-provider should fail
+// loudly.
+func New(provider string, cfg Config) (Backend, error) {
+	factory, ok := registry[strings.ToLower(provider)]
+	if !ok {
+		return nil, fmt.Errorf("unknown LLM provider %q (known providers: %s)", provider, strings.Join(Providers(), ", "))
+	}
+	return factory(cfg)
+}
@@ -0,0 +1,99 @@
+package llmbackend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+func init() {
+	RegisterProvider("ollama", newOllamaBackend)
+}
+
+// ollamaBackend talks to Ollama's native /api/generate endpoint rather than
+// its OpenAI-compatible shim, since the native API exposes options (like
+// num_predict) the shim doesn't translate cleanly.
+type ollamaBackend struct {
+	host  string
+	model string
+}
+
+func (b *ollamaBackend) Name() string { return "ollama" }
+
+type ollamaGenerateRequest struct {
+	Model   string                 `json:"model"`
+	Prompt  string                 `json:"prompt"`
+	Stream  bool                   `json:"stream"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+type ollamaGenerateResponse struct {
+	Response string `json:"response"`
+	Error    string `json:"error"`
+}
+
+func (b *ollamaBackend) Generate(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	reqBody := ollamaGenerateRequest{
+		Model:  b.model,
+		Prompt: prompt,
+		Stream: false,
+		Options: map[string]interface{}{
+			"temperature": opts.Temperature,
+			"num_predict": opts.MaxTokens,
+		},
+	}
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Ollama request: %w", err)
+	}
+
+	url := strings.TrimSuffix(b.host, "/") + "/api/generate"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to build Ollama request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ollama request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Ollama response: %w", err)
+	}
+
+	var result ollamaGenerateResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to parse Ollama response: %w", err)
+	}
+	if result.Error != "" {
+		return "", fmt.Errorf("ollama error: %s", result.Error)
+	}
+
+	return result.Response, nil
+}
+
+func newOllamaBackend(cfg Config) (Backend, error) {
+	host := cfg.BaseURL
+	if host == "" {
+		host = os.Getenv("OLLAMA_HOST")
+	}
+	if host == "" {
+		host = "http://localhost:11434"
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = "llama3"
+	}
+
+	return &ollamaBackend{host: host, model: model}, nil
+}
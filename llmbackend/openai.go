@@ -0,0 +1,109 @@
+package llmbackend
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/openai"
+)
+
+func init() {
+	// vLLM and LiteLLM both speak the OpenAI-compatible /v1/chat/completions
+	// API; they only differ in their default port, so they share the same
+	// backend with different defaultBaseURL presets.
+	RegisterProvider("vllm", func(cfg Config) (Backend, error) {
+		return newOpenAIBackend(cfg, "http://localhost:8000/v1", "qwen3")
+	})
+	RegisterProvider("litellm", func(cfg Config) (Backend, error) {
+		return newOpenAIBackend(cfg, "http://localhost:4000/v1", "qwen3")
+	})
+	RegisterProvider("openai", func(cfg Config) (Backend, error) {
+		return newOpenAIBackend(cfg, "https://api.openai.com/v1", "gpt-4o-mini")
+	})
+}
+
+// openaiBackend wraps an OpenAI-compatible chat completions endpoint via
+// LangChainGo. This covers the OpenAI API itself as well as any
+// self-hosted server speaking its protocol (vLLM, LiteLLM, etc).
+type openaiBackend struct {
+	name string
+	llm  llms.Model
+}
+
+func (b *openaiBackend) Name() string { return b.name }
+
+func (b *openaiBackend) Generate(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	completion, err := b.llm.Call(ctx, prompt, llms.WithTemperature(opts.Temperature), llms.WithMaxTokens(opts.MaxTokens))
+	if err != nil {
+		return "", fmt.Errorf("%s LLM call failed: %w", b.name, err)
+	}
+	return completion, nil
+}
+
+// GenerateJSON asks the endpoint to constrain its output to valid JSON via
+// OpenAI's response_format: json_object, which LangChainGo exposes as
+// llms.WithJSONMode. This satisfies llmbackend.JSONCapable.
+func (b *openaiBackend) GenerateJSON(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	completion, err := b.llm.Call(ctx, prompt,
+		llms.WithTemperature(opts.Temperature),
+		llms.WithMaxTokens(opts.MaxTokens),
+		llms.WithJSONMode(),
+	)
+	if err != nil {
+		return "", fmt.Errorf("%s LLM JSON-mode call failed: %w", b.name, err)
+	}
+	return completion, nil
+}
+
+func newOpenAIBackend(cfg Config, defaultBaseURL, defaultModel string) (Backend, error) {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	// Normalize URL - remove /chat/completions if present (should be base URL).
+	baseURL = strings.TrimSuffix(baseURL, "/chat/completions")
+	baseURL = strings.TrimSuffix(baseURL, "/v1/chat/completions")
+	if !strings.HasSuffix(baseURL, "/v1") {
+		if strings.HasSuffix(baseURL, "/") {
+			baseURL += "v1"
+		} else {
+			baseURL += "/v1"
+		}
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = defaultModel
+	}
+
+	// LangChainGo requires an API key, but self-hosted servers like vLLM
+	// don't validate it. Fall back to the environment, then a dummy value.
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("OPENAI_API_KEY")
+	}
+	if apiKey == "" {
+		apiKey = "not-needed"
+	}
+	// LangChainGo's openai client reads OPENAI_API_KEY from the environment
+	// internally even when a token is passed explicitly; set it so that
+	// still works for self-hosted servers that never had it configured.
+	if os.Getenv("OPENAI_API_KEY") == "" {
+		os.Setenv("OPENAI_API_KEY", apiKey)
+	}
+
+	llm, err := openai.New(
+		openai.WithBaseURL(baseURL),
+		openai.WithModel(model),
+		openai.WithAPIType(openai.APITypeOpenAI),
+		openai.WithToken(apiKey),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize LangChainGo OpenAI-compatible client: %w", err)
+	}
+
+	return &openaiBackend{name: "openai-compatible", llm: llm}, nil
+}
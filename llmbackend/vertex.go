@@ -0,0 +1,141 @@
+package llmbackend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+func init() {
+	RegisterProvider("vertex", newVertexBackend)
+}
+
+// vertexBackend calls the Vertex AI generateContent REST endpoint for
+// Gemini models directly.
+//
+// Authentication: Vertex AI expects a short-lived OAuth2 bearer token, not a
+// static API key. Minting one from a service account is out of scope here
+// (it needs the full google.golang.org/api/option credential chain); this
+// backend expects the caller to supply an already-minted access token via
+// --llm-key or GOOGLE_ACCESS_TOKEN (e.g. the output of
+// `gcloud auth print-access-token` in CI).
+type vertexBackend struct {
+	accessToken string
+	project     string
+	location    string
+	model       string
+}
+
+func (b *vertexBackend) Name() string { return "vertex" }
+
+type vertexContent struct {
+	Role  string `json:"role"`
+	Parts []struct {
+		Text string `json:"text"`
+	} `json:"parts"`
+}
+
+type vertexRequest struct {
+	Contents         []vertexContent `json:"contents"`
+	GenerationConfig struct {
+		Temperature     float64 `json:"temperature"`
+		MaxOutputTokens int     `json:"maxOutputTokens"`
+	} `json:"generationConfig"`
+}
+
+type vertexResponse struct {
+	Candidates []struct {
+		Content struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"content"`
+	} `json:"candidates"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (b *vertexBackend) Generate(ctx context.Context, prompt string, opts GenerateOptions) (string, error) {
+	reqBody := vertexRequest{
+		Contents: []vertexContent{{
+			Role: "user",
+			Parts: []struct {
+				Text string `json:"text"`
+			}{{Text: prompt}},
+		}},
+	}
+	reqBody.GenerationConfig.Temperature = opts.Temperature
+	reqBody.GenerationConfig.MaxOutputTokens = opts.MaxTokens
+
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Vertex AI request: %w", err)
+	}
+
+	url := fmt.Sprintf(
+		"https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/publishers/google/models/%s:generateContent",
+		b.location, b.project, b.location, b.model,
+	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to build Vertex AI request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+b.accessToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vertex AI request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Vertex AI response: %w", err)
+	}
+
+	var result vertexResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to parse Vertex AI response: %w", err)
+	}
+	if result.Error != nil {
+		return "", fmt.Errorf("vertex AI error: %s", result.Error.Message)
+	}
+	if len(result.Candidates) == 0 || len(result.Candidates[0].Content.Parts) == 0 {
+		return "", fmt.Errorf("vertex AI response contained no candidates")
+	}
+
+	return result.Candidates[0].Content.Parts[0].Text, nil
+}
+
+func newVertexBackend(cfg Config) (Backend, error) {
+	accessToken := cfg.APIKey
+	if accessToken == "" {
+		accessToken = os.Getenv("GOOGLE_ACCESS_TOKEN")
+	}
+	if accessToken == "" {
+		return nil, fmt.Errorf("vertex provider requires an access token (--llm-key or GOOGLE_ACCESS_TOKEN)")
+	}
+
+	project := os.Getenv("GOOGLE_CLOUD_PROJECT")
+	if project == "" {
+		return nil, fmt.Errorf("vertex provider requires GOOGLE_CLOUD_PROJECT to be set")
+	}
+
+	location := os.Getenv("VERTEX_LOCATION")
+	if location == "" {
+		location = "us-central1"
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = "gemini-1.5-pro"
+	}
+
+	return &vertexBackend{accessToken: accessToken, project: project, location: location, model: model}, nil
+}
@@ -0,0 +1,95 @@
+// Package releasenotes fetches upstream release notes for a dependency
+// version bump so the LLM can summarize authoritative changelog text
+// instead of speculating about breaking changes from a version number
+// alone. Sources are looked up by dependency.Ecosystem; each knows how to
+// turn a DependencyChange's OldVersion/NewVersion into the set of
+// intermediate-tag release notes a reviewer would actually want to read.
+package releasenotes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kaskol10/self-hosted-renovate-review/dependency"
+)
+
+// Note is a single version's release notes text from an upstream source.
+type Note struct {
+	Version string
+	Body    string
+}
+
+// Source fetches release notes for the versions between a DependencyChange's
+// OldVersion (exclusive) and NewVersion (inclusive).
+type Source interface {
+	// Name identifies the source for logging/attribution (e.g. "github",
+	// "npm", "pypi", "crates.io").
+	Name() string
+	Fetch(ctx context.Context, change dependency.DependencyChange) ([]Note, error)
+}
+
+// maxWordsPerNote bounds how much changelog text per version is passed into
+// the prompt. This is a word count, not a true token count, but it's a
+// simple and conservative enough proxy for the budget we actually care
+// about (keeping N release bodies from blowing the context window).
+const maxWordsPerNote = 400
+
+// Truncate caps body to at most maxWordsPerNote words, the same budget
+// FetchAll applies to every Note.Body before it's added to the prompt.
+func Truncate(body string) string {
+	words := strings.Fields(body)
+	if len(words) <= maxWordsPerNote {
+		return body
+	}
+	return strings.Join(words[:maxWordsPerNote], " ") + " ... (truncated)"
+}
+
+// FetchAll runs Fetch for every change against its ecosystem's Source (if
+// one is registered in sources), skipping changes with no matching source or
+// with versions that don't resolve to a fetchable range. Fetch errors are
+// collected as a note rather than failing the whole batch, since a single
+// unreachable registry shouldn't block the rest of the PR's analysis.
+func FetchAll(ctx context.Context, sources map[dependency.Ecosystem]Source, changes []dependency.DependencyChange) map[string][]Note {
+	results := make(map[string][]Note)
+	for _, change := range changes {
+		source, ok := sources[change.Ecosystem]
+		if !ok {
+			continue
+		}
+		key := fmt.Sprintf("%s (%s)", change.Name, change.Ecosystem)
+		notes, err := source.Fetch(ctx, change)
+		if err != nil {
+			results[key] = []Note{{Version: change.NewVersion, Body: fmt.Sprintf("(failed to fetch release notes from %s: %v)", source.Name(), err)}}
+			continue
+		}
+		for i := range notes {
+			notes[i].Body = Truncate(notes[i].Body)
+		}
+		results[key] = notes
+	}
+	return results
+}
+
+// Format renders FetchAll's output as markdown for injection into the LLM
+// prompt's release notes section.
+func Format(notesByChange map[string][]Note) string {
+	if len(notesByChange) == 0 {
+		return "No release notes were available for the detected dependency changes."
+	}
+
+	var b strings.Builder
+	for name, notes := range notesByChange {
+		if len(notes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n**%s:**\n", name)
+		for _, note := range notes {
+			fmt.Fprintf(&b, "\n_%s_\n%s\n", note.Version, note.Body)
+		}
+	}
+	if b.Len() == 0 {
+		return "No release notes were available for the detected dependency changes."
+	}
+	return b.String()
+}
@@ -0,0 +1,255 @@
+package releasenotes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+
+	"github.com/kaskol10/self-hosted-renovate-review/dependency"
+)
+
+// GitHubSource fetches release notes via the GitHub Releases API, using the
+// go-github client the analyzer already has wired up for PR access. It
+// expects change.Name to be (or contain) an "owner/repo" path, which is how
+// Go module paths (github.com/owner/repo[/subpath]) and most npm/crates
+// "repository" fields are shaped.
+type GitHubSource struct {
+	Client *github.Client
+}
+
+func (s GitHubSource) Name() string { return "github" }
+
+// Fetch lists repository releases and returns the bodies of every release
+// whose tag falls in (change.OldVersion, change.NewVersion].
+func (s GitHubSource) Fetch(ctx context.Context, change dependency.DependencyChange) ([]Note, error) {
+	owner, repo, ok := ownerRepoFromModulePath(change.Name)
+	if !ok {
+		return nil, fmt.Errorf("could not determine GitHub owner/repo from %q", change.Name)
+	}
+
+	releases, _, err := s.Client.Repositories.ListReleases(ctx, owner, repo, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases for %s/%s: %w", owner, repo, err)
+	}
+
+	var notes []Note
+	for _, rel := range releases {
+		tag := rel.GetTagName()
+		order, ok := dependency.CompareVersions(tag, change.OldVersion)
+		if !ok || order <= 0 {
+			continue
+		}
+		order, ok = dependency.CompareVersions(tag, change.NewVersion)
+		if !ok || order > 0 {
+			continue
+		}
+		notes = append(notes, Note{Version: tag, Body: rel.GetBody()})
+	}
+	return notes, nil
+}
+
+// ownerRepoFromModulePath extracts "owner/repo" from strings shaped like
+// "github.com/owner/repo", "github.com/owner/repo/v2", or a bare
+// "owner/repo".
+func ownerRepoFromModulePath(name string) (owner, repo string, ok bool) {
+	name = strings.TrimPrefix(name, "https://")
+	name = strings.TrimPrefix(name, "github.com/")
+	parts := strings.Split(name, "/")
+	if len(parts) < 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// httpGetJSON is a small shared helper: GET a URL and decode the JSON body
+// into v, bounding the response to avoid an unbounded read from a
+// misbehaving registry.
+func httpGetJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 5<<20)) // 5MB cap
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// sortVersions orders versions ascending by semver where possible, falling
+// back to lexicographic order for any version CompareVersions can't parse
+// (e.g. one that doesn't start with a number), so the result is
+// still deterministic even on unparseable input. A pure lexicographic sort
+// here would put "10.0.0" before "2.0.0", turning the release notes into a
+// scrambled reading order instead of a chronological one.
+func sortVersions(versions []string) {
+	sort.Slice(versions, func(i, j int) bool {
+		order, ok := dependency.CompareVersions(versions[i], versions[j])
+		if !ok {
+			return versions[i] < versions[j]
+		}
+		return order < 0
+	})
+}
+
+// NPMSource fetches package metadata from the npm registry. npm doesn't
+// expose per-version changelog text in its registry API, so this surfaces
+// the closest authoritative thing it does offer: each intermediate
+// version's own package description plus a link to its repository, which is
+// enough for the LLM to go look, and is still strictly better than nothing.
+type NPMSource struct{}
+
+func (s NPMSource) Name() string { return "npm" }
+
+type npmPackageMeta struct {
+	Versions map[string]struct {
+		Description string `json:"description"`
+		Repository  struct {
+			URL string `json:"url"`
+		} `json:"repository"`
+	} `json:"versions"`
+}
+
+func (s NPMSource) Fetch(ctx context.Context, change dependency.DependencyChange) ([]Note, error) {
+	var meta npmPackageMeta
+	url := fmt.Sprintf("https://registry.npmjs.org/%s", change.Name)
+	if err := httpGetJSON(ctx, url, &meta); err != nil {
+		return nil, fmt.Errorf("failed to fetch npm metadata for %s: %w", change.Name, err)
+	}
+
+	versions := make([]string, 0, len(meta.Versions))
+	for version := range meta.Versions {
+		versions = append(versions, version)
+	}
+	sortVersions(versions)
+
+	var notes []Note
+	for _, version := range versions {
+		order, ok := dependency.CompareVersions(version, change.OldVersion)
+		if !ok || order <= 0 {
+			continue
+		}
+		order, ok = dependency.CompareVersions(version, change.NewVersion)
+		if !ok || order > 0 {
+			continue
+		}
+		info := meta.Versions[version]
+		body := info.Description
+		if info.Repository.URL != "" {
+			body = fmt.Sprintf("%s (repository: %s)", body, info.Repository.URL)
+		}
+		notes = append(notes, Note{Version: version, Body: body})
+	}
+	return notes, nil
+}
+
+// PyPISource fetches package metadata from the PyPI JSON API. Like npm,
+// PyPI's JSON API doesn't carry a structured per-version changelog, so this
+// surfaces the project's summary from the package-level info block, the
+// same text for each intermediate version, as the closest available
+// authoritative text.
+type PyPISource struct{}
+
+func (s PyPISource) Name() string { return "pypi" }
+
+type pypiPackageMeta struct {
+	Info struct {
+		Summary string `json:"summary"`
+	} `json:"info"`
+	Releases map[string][]struct{} `json:"releases"`
+}
+
+func (s PyPISource) Fetch(ctx context.Context, change dependency.DependencyChange) ([]Note, error) {
+	var meta pypiPackageMeta
+	url := fmt.Sprintf("https://pypi.org/pypi/%s/json", change.Name)
+	if err := httpGetJSON(ctx, url, &meta); err != nil {
+		return nil, fmt.Errorf("failed to fetch PyPI metadata for %s: %w", change.Name, err)
+	}
+
+	versions := make([]string, 0, len(meta.Releases))
+	for version := range meta.Releases {
+		versions = append(versions, version)
+	}
+	sortVersions(versions)
+
+	var notes []Note
+	for _, version := range versions {
+		order, ok := dependency.CompareVersions(version, change.OldVersion)
+		if !ok || order <= 0 {
+			continue
+		}
+		order, ok = dependency.CompareVersions(version, change.NewVersion)
+		if !ok || order > 0 {
+			continue
+		}
+		notes = append(notes, Note{Version: version, Body: meta.Info.Summary})
+	}
+	return notes, nil
+}
+
+// CratesSource fetches crate metadata from crates.io. The crates.io API
+// lists versions but, like npm/PyPI, doesn't carry per-version changelog
+// text, so this surfaces the crate's own description alongside each
+// intermediate version number.
+type CratesSource struct{}
+
+func (s CratesSource) Name() string { return "crates.io" }
+
+type cratesPackageMeta struct {
+	Crate struct {
+		Description string `json:"description"`
+	} `json:"crate"`
+	Versions []struct {
+		Num string `json:"num"`
+	} `json:"versions"`
+}
+
+func (s CratesSource) Fetch(ctx context.Context, change dependency.DependencyChange) ([]Note, error) {
+	var meta cratesPackageMeta
+	url := fmt.Sprintf("https://crates.io/api/v1/crates/%s", change.Name)
+	if err := httpGetJSON(ctx, url, &meta); err != nil {
+		return nil, fmt.Errorf("failed to fetch crates.io metadata for %s: %w", change.Name, err)
+	}
+
+	var notes []Note
+	for _, v := range meta.Versions {
+		order, ok := dependency.CompareVersions(v.Num, change.OldVersion)
+		if !ok || order <= 0 {
+			continue
+		}
+		order, ok = dependency.CompareVersions(v.Num, change.NewVersion)
+		if !ok || order > 0 {
+			continue
+		}
+		notes = append(notes, Note{Version: v.Num, Body: meta.Crate.Description})
+	}
+	return notes, nil
+}
+
+// DefaultSources returns the standard ecosystem -> Source mapping, wiring
+// GitHubSource to the same authenticated client the analyzer uses for PR
+// access.
+func DefaultSources(ghClient *github.Client) map[dependency.Ecosystem]Source {
+	return map[dependency.Ecosystem]Source{
+		dependency.EcosystemGo:     GitHubSource{Client: ghClient},
+		dependency.EcosystemNPM:    NPMSource{},
+		dependency.EcosystemPython: PyPISource{},
+		dependency.EcosystemRust:   CratesSource{},
+	}
+}
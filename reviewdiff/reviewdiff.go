@@ -0,0 +1,72 @@
+// Package reviewdiff parses unified-diff hunks well enough to anchor a
+// review comment to the actual line in the PR's new file version, the way
+// GitHub's PullRequests.CreateReview API expects (Path + Line on the RIGHT
+// side), instead of posting one large markdown comment that reviewers
+// scroll past.
+package reviewdiff
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AddedLine is a single added or modified line from a diff hunk, tagged with
+// its line number in the new version of the file.
+type AddedLine struct {
+	LineNo  int
+	Content string
+}
+
+var hunkHeaderRe = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// ParseAddedLines walks a unified diff patch (as returned in
+// github.CommitFile.Patch) and returns every added/modified line along with
+// its line number in the new file. Context and removed lines are not
+// returned since they can't be commented on via the RIGHT side of a review.
+func ParseAddedLines(patch string) []AddedLine {
+	var added []AddedLine
+	newLineNo := 0
+
+	for _, line := range strings.Split(patch, "\n") {
+		if m := hunkHeaderRe.FindStringSubmatch(line); m != nil {
+			start, err := strconv.Atoi(m[1])
+			if err == nil {
+				newLineNo = start - 1
+			}
+			continue
+		}
+		if len(line) == 0 {
+			continue
+		}
+		switch line[0] {
+		case '+':
+			if strings.HasPrefix(line, "+++") {
+				continue
+			}
+			newLineNo++
+			added = append(added, AddedLine{LineNo: newLineNo, Content: line[1:]})
+		case '-':
+			// Removed lines don't exist in the new file; don't advance.
+		default:
+			newLineNo++
+		}
+	}
+	return added
+}
+
+// FindLine returns the line number of the first added line whose content
+// contains needle, or 0 if none matches. It's a best-effort way to anchor a
+// finding about a specific value (e.g. a new dependency version) to the
+// diff line that introduced it.
+func FindLine(added []AddedLine, needle string) int {
+	if needle == "" {
+		return 0
+	}
+	for _, l := range added {
+		if strings.Contains(l.Content, needle) {
+			return l.LineNo
+		}
+	}
+	return 0
+}
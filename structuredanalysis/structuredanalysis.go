@@ -0,0 +1,227 @@
+// Package structuredanalysis defines the machine-consumable shape of a PR
+// analysis result: a Go struct the LLM's JSON output is validated against,
+// rather than markdown prose the tool has to trust the model formatted
+// correctly. Rendering to markdown happens in Go from the validated struct,
+// not from LLM-authored text, so the comment layout never depends on the
+// model following the template exactly.
+package structuredanalysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Risk is the severity the model assigned a single dependency change.
+type Risk string
+
+const (
+	RiskLow    Risk = "low"
+	RiskMedium Risk = "medium"
+	RiskHigh   Risk = "high"
+)
+
+func (r Risk) valid() bool {
+	switch r {
+	case RiskLow, RiskMedium, RiskHigh:
+		return true
+	default:
+		return false
+	}
+}
+
+var riskRank = map[Risk]int{RiskLow: 0, RiskMedium: 1, RiskHigh: 2}
+
+// AtLeast reports whether r is at least as severe as threshold (e.g. for a
+// --fail-on-risk=high CI gate). An unrecognized threshold never matches, and
+// neither does an unrecognized r (including the zero value, which
+// HighestRisk returns when there were no changes to rank) - otherwise it
+// would rank equal to RiskLow purely because riskRank[""] defaults to 0.
+func (r Risk) AtLeast(threshold Risk) bool {
+	if !r.valid() {
+		return false
+	}
+	rank, ok := riskRank[threshold]
+	if !ok {
+		return false
+	}
+	return riskRank[r] >= rank
+}
+
+// Recommendation is the model's overall merge recommendation.
+type Recommendation string
+
+const (
+	RecommendationMerge        Recommendation = "merge"
+	RecommendationReviewNeeded Recommendation = "review_required"
+	RecommendationDoNotMerge   Recommendation = "do_not_merge"
+)
+
+func (r Recommendation) valid() bool {
+	switch r {
+	case RecommendationMerge, RecommendationReviewNeeded, RecommendationDoNotMerge:
+		return true
+	default:
+		return false
+	}
+}
+
+// ChangeAssessment is the model's assessment of a single dependency change.
+type ChangeAssessment struct {
+	Name            string   `json:"name"`
+	Old             string   `json:"old"`
+	New             string   `json:"new"`
+	Risk            Risk     `json:"risk"`
+	BreakingChanges []string `json:"breaking_changes"`
+	MigrationSteps  []string `json:"migration_steps"`
+}
+
+// Result is the full structured analysis the LLM is asked to produce.
+type Result struct {
+	Summary        string             `json:"summary"`
+	Changes        []ChangeAssessment `json:"changes"`
+	Recommendation Recommendation     `json:"recommendation"`
+}
+
+// Parse unmarshals and validates raw LLM output as a Result. raw is
+// trimmed of markdown code fences first, since models asked for JSON
+// frequently wrap it in ```json ... ``` anyway.
+func Parse(raw string) (Result, error) {
+	var result Result
+	clean := stripCodeFence(raw)
+	if err := json.Unmarshal([]byte(clean), &result); err != nil {
+		return Result{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if err := result.Validate(); err != nil {
+		return Result{}, err
+	}
+	return result, nil
+}
+
+// Validate checks the struct satisfies the constraints the prompt asked
+// for, beyond what JSON unmarshaling alone guarantees (valid enum values,
+// a non-empty summary).
+func (r Result) Validate() error {
+	if strings.TrimSpace(r.Summary) == "" {
+		return fmt.Errorf("summary must not be empty")
+	}
+	if !r.Recommendation.valid() {
+		return fmt.Errorf("recommendation %q is not one of merge/review_required/do_not_merge", r.Recommendation)
+	}
+	for i, c := range r.Changes {
+		if !c.Risk.valid() {
+			return fmt.Errorf("changes[%d].risk %q is not one of low/medium/high", i, c.Risk)
+		}
+	}
+	return nil
+}
+
+// stripCodeFence removes a leading/trailing ``` or ```json fence if present.
+func stripCodeFence(raw string) string {
+	s := strings.TrimSpace(raw)
+	if !strings.HasPrefix(s, "```") {
+		return s
+	}
+	s = strings.TrimPrefix(s, "```json")
+	s = strings.TrimPrefix(s, "```")
+	s = strings.TrimSuffix(s, "```")
+	return strings.TrimSpace(s)
+}
+
+// SchemaInstructions describes the required JSON shape in prose, for
+// backends that don't support enforced JSON-schema output and need the
+// schema spelled out in the prompt itself.
+const SchemaInstructions = `Respond with ONLY a single JSON object (no markdown fences, no commentary before or after) matching exactly this shape:
+
+{
+  "summary": "<overall one-paragraph summary>",
+  "changes": [
+    {
+      "name": "<dependency name>",
+      "old": "<old version>",
+      "new": "<new version>",
+      "risk": "<low|medium|high>",
+      "breaking_changes": ["<concrete breaking change>", "..."],
+      "migration_steps": ["<concrete step>", "..."]
+    }
+  ],
+  "recommendation": "<merge|review_required|do_not_merge>"
+}`
+
+// HighestRisk returns the highest Risk among the result's changes, or "" if
+// there are none. Risk ordering is low < medium < high.
+func (r Result) HighestRisk() Risk {
+	var highest Risk
+	best := -1
+	for _, c := range r.Changes {
+		if riskRank[c.Risk] > best {
+			best = riskRank[c.Risk]
+			highest = c.Risk
+		}
+	}
+	return highest
+}
+
+// RenderMarkdown deterministically builds the PR comment body from a
+// validated Result, rather than trusting the LLM to have formatted its own
+// markdown consistently.
+func RenderMarkdown(r Result) string {
+	var b strings.Builder
+
+	b.WriteString("## 🤖 Renovate AI Analysis (Structured)\n\n")
+	b.WriteString(r.Summary)
+	b.WriteString("\n\n### Dependency Changes\n\n")
+
+	if len(r.Changes) == 0 {
+		b.WriteString("No dependency changes were assessed.\n\n")
+	} else {
+		b.WriteString("| Name | Old → New | Risk |\n|---|---|---|\n")
+		for _, c := range r.Changes {
+			fmt.Fprintf(&b, "| %s | %s → %s | %s |\n", c.Name, c.Old, c.New, riskBadge(c.Risk))
+		}
+		b.WriteString("\n")
+
+		for _, c := range r.Changes {
+			if len(c.BreakingChanges) == 0 && len(c.MigrationSteps) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "**%s (%s)**\n\n", c.Name, riskBadge(c.Risk))
+			for _, bc := range c.BreakingChanges {
+				fmt.Fprintf(&b, "- ⚠️ %s\n", bc)
+			}
+			for _, ms := range c.MigrationSteps {
+				fmt.Fprintf(&b, "- ✅ %s\n", ms)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	fmt.Fprintf(&b, "### Recommendation: %s\n", recommendationBadge(r.Recommendation))
+	return b.String()
+}
+
+func riskBadge(r Risk) string {
+	switch r {
+	case RiskHigh:
+		return "🔴 HIGH"
+	case RiskMedium:
+		return "🟡 MEDIUM"
+	case RiskLow:
+		return "🟢 LOW"
+	default:
+		return string(r)
+	}
+}
+
+func recommendationBadge(r Recommendation) string {
+	switch r {
+	case RecommendationMerge:
+		return "✅ MERGE"
+	case RecommendationReviewNeeded:
+		return "⚠️ REVIEW REQUIRED"
+	case RecommendationDoNotMerge:
+		return "❌ DO NOT MERGE"
+	default:
+		return string(r)
+	}
+}